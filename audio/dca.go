@@ -0,0 +1,371 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/fvdveen/fuzzy"
+)
+
+const (
+	// dcaMagic starts a DCA v1 stream
+	dcaMagic = "DCA1"
+
+	// maxDCAMetadata is the largest metadata header that is read, larger headers are not valid
+	maxDCAMetadata = 1 << 20
+)
+
+// DCAMetadata is the JSON header of a DCA v1 stream
+type DCAMetadata struct {
+	DCA    DCAInfo         `json:"dca"`
+	Opus   DCAOpus         `json:"opus"`
+	Info   DCASongInfo     `json:"info"`
+	Origin DCAOrigin       `json:"origin"`
+	Extra  json.RawMessage `json:"extra,omitempty"`
+}
+
+// DCAInfo describes the DCA version and the tool which wrote the stream
+type DCAInfo struct {
+	Version int     `json:"version"`
+	Tool    DCATool `json:"tool"`
+}
+
+// DCATool is the tool which wrote a DCA stream
+type DCATool struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	URL     string `json:"url"`
+	Author  string `json:"author"`
+}
+
+// DCAOpus describes the opus encoding of a DCA stream
+type DCAOpus struct {
+	Mode       string `json:"mode"`
+	SampleRate int    `json:"sample_rate"`
+	FrameSize  int    `json:"frame_size"`
+	Bitrate    int    `json:"abr"`
+	VBR        bool   `json:"vbr"`
+	Channels   int    `json:"channels"`
+}
+
+// DCASongInfo describes the song of a DCA stream
+type DCASongInfo struct {
+	Title    string `json:"title"`
+	Artist   string `json:"artist"`
+	Album    string `json:"album"`
+	Genre    string `json:"genre"`
+	Comments string `json:"comments"`
+	// Cover is the base64 encoded cover image
+	Cover string `json:"cover"`
+}
+
+// DCAOrigin describes the source of a DCA stream
+type DCAOrigin struct {
+	Source   string `json:"source"`
+	Bitrate  int    `json:"abr"`
+	Channels int    `json:"channels"`
+	Encoding string `json:"encoding"`
+	URL      string `json:"url"`
+}
+
+// trackMetadata converts the header of a DCA stream to the metadata of a track
+func (m *DCAMetadata) trackMetadata(frames int) fuzzy.Metadata {
+	md := fuzzy.Metadata{Duration: time.Duration(frames) * FrameDuration}
+	if m != nil {
+		md.Title = m.Info.Title
+		md.Artist = m.Info.Artist
+		md.URL = m.Origin.URL
+	}
+	return md
+}
+
+// validate returns an error when the opus audio described by the header can not be sent to discord
+// fields which are not set are not checked
+func (m *DCAMetadata) validate() error {
+	o := m.Opus
+	switch {
+	case o.Channels != 0 && o.Channels != Channels:
+		return fmt.Errorf("%v: %d channels, discord needs %d", ErrUnsupportedOpus, o.Channels, Channels)
+	case o.SampleRate != 0 && o.SampleRate != SampleRate:
+		return fmt.Errorf("%v: sample rate of %dHz, discord needs %dHz", ErrUnsupportedOpus, o.SampleRate, SampleRate)
+	case o.FrameSize != 0 && o.FrameSize != SampleRate/50:
+		return fmt.Errorf("%v: frame size of %d samples", ErrUnsupportedOpus, o.FrameSize)
+	}
+	return nil
+}
+
+// readDCAHeader reads the header of a DCA v1 stream
+// a stream without a header is a DCA v0 stream, the bytes read from it are returned as they start its first frame
+func readDCAHeader(r io.Reader) (*DCAMetadata, []byte, error) {
+	b := make([]byte, len(dcaMagic))
+	n, err := io.ReadFull(r, b)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return nil, b[:n], nil
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	if string(b) != dcaMagic {
+		if string(b[:3]) == dcaMagic[:3] {
+			return nil, nil, fmt.Errorf("%v: version %c", ErrUnsupportedDCA, b[3])
+		}
+		return nil, b, nil
+	}
+
+	var l int32
+	if err := binary.Read(r, binary.LittleEndian, &l); err != nil {
+		return nil, nil, fmt.Errorf("%v: no metadata length", ErrInvalidDCA)
+	}
+	if l < 0 {
+		return nil, nil, fmt.Errorf("%v: negative metadata length", ErrInvalidDCA)
+	}
+	if l > maxDCAMetadata {
+		return nil, nil, fmt.Errorf("%v: metadata of %d bytes, the limit is %d", ErrInvalidDCA, l, maxDCAMetadata)
+	}
+	data := make([]byte, l)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, nil, fmt.Errorf("%v: could not read metadata: %v", ErrInvalidDCA, err)
+	}
+
+	m := &DCAMetadata{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, nil, fmt.Errorf("%v: could not decode metadata: %v", ErrInvalidDCA, err)
+	}
+	if err := m.validate(); err != nil {
+		return nil, nil, err
+	}
+	return m, nil, nil
+}
+
+// readDCAFrame reads a length prefixed opus frame, it returns io.EOF at the end of the stream
+func readDCAFrame(r io.Reader) ([]byte, error) {
+	var l int16
+	if err := binary.Read(r, binary.LittleEndian, &l); err == io.ErrUnexpectedEOF {
+		return nil, fmt.Errorf("%v: incomplete frame length", ErrInvalidDCA)
+	} else if err != nil {
+		return nil, err
+	}
+	if l < 0 {
+		return nil, fmt.Errorf("%v: negative frame length", ErrInvalidDCA)
+	}
+
+	f := make([]byte, l)
+	if _, err := io.ReadFull(r, f); err != nil {
+		return nil, fmt.Errorf("%v: incomplete frame: %v", ErrInvalidDCA, err)
+	}
+	if err := checkFrame(f); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// DCAItem is a fuzzy.VoiceItem playing a DCA v0 or v1 stream
+// the frames are read from the stream while they are played
+type DCAItem struct {
+	r      io.ReadSeeker
+	header *DCAMetadata
+	// start is the offset of the first frame
+	start  int64
+	frames int
+	err    error
+}
+
+var _ fuzzy.MetadataItem = (*DCAItem)(nil)
+
+// OpenDCA opens the DCA file at path, the item must be closed when it is no longer played
+func OpenDCA(path string) (*DCAItem, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := NewDCAItem(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("could not read %s: %v", path, err)
+	}
+	return d, nil
+}
+
+// NewDCAItem reads the header of the DCA stream and counts its frames
+func NewDCAItem(r io.ReadSeeker) (*DCAItem, error) {
+	d := &DCAItem{r: r}
+
+	var (
+		prefix []byte
+		err    error
+	)
+	if d.header, prefix, err = readDCAHeader(r); err != nil {
+		return nil, err
+	}
+	if d.start, err = r.Seek(-int64(len(prefix)), io.SeekCurrent); err != nil {
+		return nil, err
+	}
+
+	if err := d.countFrames(); err != nil {
+		return nil, err
+	}
+	if _, err := r.Seek(d.start, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// countFrames skips over all frames to count them
+func (d *DCAItem) countFrames() error {
+	end, err := d.r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	if _, err := d.r.Seek(d.start, io.SeekStart); err != nil {
+		return err
+	}
+
+	for {
+		var l int16
+		if err := binary.Read(d.r, binary.LittleEndian, &l); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("%v: incomplete frame length", ErrInvalidDCA)
+		}
+		if l < 0 {
+			return fmt.Errorf("%v: negative frame length", ErrInvalidDCA)
+		}
+		pos, err := d.r.Seek(int64(l), io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+		if pos > end {
+			return fmt.Errorf("%v: incomplete frame", ErrInvalidDCA)
+		}
+		d.frames++
+	}
+}
+
+// OpusFrame reads the next frame of the stream
+func (d *DCAItem) OpusFrame() ([]byte, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	return readDCAFrame(d.r)
+}
+
+// ResetPlayback seeks back to the first frame
+func (d *DCAItem) ResetPlayback() {
+	_, d.err = d.r.Seek(d.start, io.SeekStart)
+}
+
+// Version returns the DCA version of the stream
+func (d *DCAItem) Version() int {
+	if d.header == nil {
+		return 0
+	}
+	return 1
+}
+
+// Header returns the JSON header of a DCA v1 stream or nil for a DCA v0 stream
+func (d *DCAItem) Header() *DCAMetadata {
+	return d.header
+}
+
+// Duration returns the duration of the stream
+func (d *DCAItem) Duration() time.Duration {
+	return time.Duration(d.frames) * FrameDuration
+}
+
+// Metadata returns the song info of the header and the duration of the stream
+func (d *DCAItem) Metadata() fuzzy.Metadata {
+	return d.header.trackMetadata(d.frames)
+}
+
+// Close closes the reader of the item if it is an io.Closer
+func (d *DCAItem) Close() error {
+	if c, ok := d.r.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// CachedDCA is a fuzzy.VoiceItem playing a DCA stream which is held in memory
+// it is meant for short sounds which are played often, use Copy to play it in several guilds at once
+type CachedDCA struct {
+	header *DCAMetadata
+	frames [][]byte
+	i      int
+}
+
+var _ fuzzy.MetadataItem = (*CachedDCA)(nil)
+
+// LoadDCA reads the whole DCA stream into memory
+func LoadDCA(r io.Reader) (*CachedDCA, error) {
+	h, prefix, err := readDCAHeader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &CachedDCA{header: h}
+	r = io.MultiReader(bytes.NewReader(prefix), r)
+	for {
+		f, err := readDCAFrame(r)
+		if err == io.EOF {
+			return c, nil
+		} else if err != nil {
+			return nil, err
+		}
+		c.frames = append(c.frames, f)
+	}
+}
+
+// LoadDCAFile reads the whole DCA file at path into memory
+func LoadDCAFile(path string) (*CachedDCA, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := LoadDCA(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %v", path, err)
+	}
+	return c, nil
+}
+
+// OpusFrame returns the next frame
+func (c *CachedDCA) OpusFrame() ([]byte, error) {
+	if c.i >= len(c.frames) {
+		return nil, io.EOF
+	}
+	c.i++
+	return c.frames[c.i-1], nil
+}
+
+// ResetPlayback starts playing from the first frame
+func (c *CachedDCA) ResetPlayback() {
+	c.i = 0
+}
+
+// Copy returns an item playing the same frames from the start
+func (c *CachedDCA) Copy() *CachedDCA {
+	return &CachedDCA{header: c.header, frames: c.frames}
+}
+
+// Header returns the JSON header of a DCA v1 stream or nil for a DCA v0 stream
+func (c *CachedDCA) Header() *DCAMetadata {
+	return c.header
+}
+
+// Duration returns the duration of the sound
+func (c *CachedDCA) Duration() time.Duration {
+	return time.Duration(len(c.frames)) * FrameDuration
+}
+
+// Metadata returns the song info of the header and the duration of the sound
+func (c *CachedDCA) Metadata() fuzzy.Metadata {
+	return c.header.trackMetadata(len(c.frames))
+}
@@ -0,0 +1,157 @@
+package audio_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fvdveen/fuzzy"
+	"github.com/fvdveen/fuzzy/audio"
+)
+
+// dca writes the frames as a DCA stream, with a v1 header when meta is not nil
+func dca(meta interface{}, frames ...[]byte) []byte {
+	var b bytes.Buffer
+	if meta != nil {
+		data, _ := json.Marshal(meta)
+		b.WriteString("DCA1")
+		_ = binary.Write(&b, binary.LittleEndian, int32(len(data)))
+		b.Write(data)
+	}
+	for _, f := range frames {
+		_ = binary.Write(&b, binary.LittleEndian, int16(len(f)))
+		b.Write(f)
+	}
+	return b.Bytes()
+}
+
+// playAll reads all frames of the item
+func playAll(t *testing.T, vi fuzzy.VoiceItem) [][]byte {
+	t.Helper()
+
+	var fs [][]byte
+	for {
+		f, err := vi.OpusFrame()
+		if err == io.EOF {
+			return fs
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		fs = append(fs, f)
+	}
+}
+
+func TestDCA(t *testing.T) {
+	frames := [][]byte{frame(10, 1), frame(30, 2), frame(20, 3)}
+	meta := &audio.DCAMetadata{
+		DCA:    audio.DCAInfo{Version: 1},
+		Opus:   audio.DCAOpus{SampleRate: 48000, FrameSize: 960, Channels: 2},
+		Info:   audio.DCASongInfo{Title: "Song", Artist: "Band"},
+		Origin: audio.DCAOrigin{URL: "https://example.com/song"},
+	}
+
+	tests := []struct {
+		name string
+		meta *audio.DCAMetadata
+		md   fuzzy.Metadata
+	}{
+		{"v0", nil, fuzzy.Metadata{Duration: 60 * time.Millisecond}},
+		{"v1", meta, fuzzy.Metadata{Title: "Song", Artist: "Band", URL: "https://example.com/song", Duration: 60 * time.Millisecond}},
+	}
+
+	for _, tt := range tests {
+		var m interface{}
+		if tt.meta != nil {
+			m = tt.meta
+		}
+		stream := dca(m, frames...)
+
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := audio.NewDCAItem(bytes.NewReader(stream))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(d.Header(), tt.meta) {
+				t.Errorf("expected header %+v got %+v", tt.meta, d.Header())
+			}
+			if md := d.Metadata(); md != tt.md {
+				t.Errorf("expected metadata %+v got %+v", tt.md, md)
+			}
+			for i := 0; i < 2; i++ {
+				if fs := playAll(t, d); !reflect.DeepEqual(fs, frames) {
+					t.Fatalf("expected frames %v got %v", frames, fs)
+				}
+				d.ResetPlayback()
+			}
+		})
+
+		t.Run(tt.name+" cached", func(t *testing.T) {
+			c, err := audio.LoadDCA(bytes.NewReader(stream))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if md := c.Metadata(); md != tt.md {
+				t.Errorf("expected metadata %+v got %+v", tt.md, md)
+			}
+
+			cp := c.Copy()
+			if fs := playAll(t, c); !reflect.DeepEqual(fs, frames) {
+				t.Fatalf("expected frames %v got %v", frames, fs)
+			}
+			if fs := playAll(t, cp); !reflect.DeepEqual(fs, frames) {
+				t.Fatalf("expected the copy to play from the start got %v", fs)
+			}
+			c.ResetPlayback()
+			if fs := playAll(t, c); !reflect.DeepEqual(fs, frames) {
+				t.Fatalf("expected frames after a reset %v got %v", frames, fs)
+			}
+		})
+	}
+}
+
+func TestDCAInvalid(t *testing.T) {
+	truncated := dca(nil, frame(10, 1))
+	truncated = truncated[:len(truncated)-1]
+
+	tests := []struct {
+		name   string
+		stream []byte
+		err    string
+	}{
+		{"empty", nil, ""},
+		{"version", []byte("DCA2\x00\x00\x00\x00"), audio.ErrUnsupportedDCA.Error()},
+		{"bad json", append([]byte("DCA1\x01\x00\x00\x00"), '{'), audio.ErrInvalidDCA.Error()},
+		{"mono", dca(audio.DCAMetadata{Opus: audio.DCAOpus{Channels: 1}}, frame(10, 1)), "1 channels"},
+		{"frame size", dca(audio.DCAMetadata{Opus: audio.DCAOpus{FrameSize: 1920}}, frame(10, 1)), "1920 samples"},
+		{"truncated", truncated, audio.ErrInvalidDCA.Error()},
+		{"negative length", []byte{0xff, 0xff}, audio.ErrInvalidDCA.Error()},
+		{"huge metadata", []byte("DCA1\xff\xff\xff\x7f{}"), "metadata of 2147483647 bytes"},
+		{"60ms frame", dca(nil, []byte{3 << 3, 0}), audio.ErrFrameDuration.Error()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, load := range []func() (fuzzy.VoiceItem, error){
+				func() (fuzzy.VoiceItem, error) { return audio.NewDCAItem(bytes.NewReader(tt.stream)) },
+				func() (fuzzy.VoiceItem, error) { return audio.LoadDCA(bytes.NewReader(tt.stream)) },
+			} {
+				vi, err := load()
+				if err == nil {
+					_, err = vi.OpusFrame()
+					if err == io.EOF {
+						err = nil
+					}
+				}
+				if tt.err == "" && err != nil || tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+					t.Fatalf("expected an error containing %q got %v", tt.err, err)
+				}
+			}
+		})
+	}
+}
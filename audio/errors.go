@@ -0,0 +1,29 @@
+package audio
+
+import "errors"
+
+var (
+	// ErrInvalidPacket is used when an opus packet could not be parsed
+	ErrInvalidPacket = errors.New("invalid opus packet")
+
+	// ErrFrameDuration is used when an opus packet is not FrameDuration long
+	ErrFrameDuration = errors.New("opus packet is not 20ms")
+
+	// ErrInvalidOgg is used when a stream is not a valid ogg stream
+	ErrInvalidOgg = errors.New("invalid ogg stream")
+
+	// ErrOggChecksum is used when the checksum of an ogg page does not match
+	ErrOggChecksum = errors.New("ogg page checksum mismatch")
+
+	// ErrNotOpus is used when an ogg stream does not hold opus audio
+	ErrNotOpus = errors.New("ogg stream is not opus")
+
+	// ErrUnsupportedOpus is used when the opus audio can not be sent to discord
+	ErrUnsupportedOpus = errors.New("unsupported opus stream")
+
+	// ErrInvalidDCA is used when a stream is not a valid DCA stream
+	ErrInvalidDCA = errors.New("invalid dca stream")
+
+	// ErrUnsupportedDCA is used when a DCA stream has an unknown version
+	ErrUnsupportedDCA = errors.New("unsupported dca version")
+)
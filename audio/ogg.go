@@ -0,0 +1,382 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fvdveen/fuzzy"
+)
+
+const (
+	oggHeaderSize = 27
+	// oggMaxPageSize is the largest possible ogg page
+	oggMaxPageSize = oggHeaderSize + 255 + 255*255
+
+	oggContinued = 0x01
+	// oggNoGranule is the granule position of pages on which no packet ends
+	oggNoGranule = ^uint64(0)
+)
+
+// OpusHead is the identification header of an ogg opus stream
+type OpusHead struct {
+	Version  uint8
+	Channels uint8
+	// PreSkip is the amount of samples to skip at the start of the stream
+	PreSkip uint16
+	// InputSampleRate is the sample rate of the audio before it was encoded, 0 if it is not known
+	// it is only informational, opus is always decoded at 48kHz
+	InputSampleRate uint32
+	OutputGain      int16
+	MappingFamily   uint8
+}
+
+// oggPage is the header of an ogg page
+type oggPage struct {
+	flags    byte
+	granule  uint64
+	serial   uint32
+	segments []byte
+}
+
+// OggItem is a fuzzy.VoiceItem playing ogg encapsulated opus audio
+// the audio must be stereo with 20ms packets
+type OggItem struct {
+	r io.ReadSeeker
+
+	head     OpusHead
+	tags     map[string]string
+	duration time.Duration
+	serial   uint32
+	// hasSerial is set once the serial of the first page is known
+	hasSerial bool
+	// start is the offset of the first audio page
+	start int64
+
+	// packets are the packets read from the current page which have not been played
+	packets [][]byte
+	// partial is a packet which continues on the next page
+	partial []byte
+	err     error
+}
+
+var _ fuzzy.MetadataItem = (*OggItem)(nil)
+
+// OpenOgg opens the ogg opus file at path, the item must be closed when it is no longer played
+func OpenOgg(path string) (*OggItem, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	o, err := NewOggItem(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("could not read %s: %v", path, err)
+	}
+	return o, nil
+}
+
+// NewOggItem reads the headers of the ogg opus stream and validates them
+func NewOggItem(r io.ReadSeeker) (*OggItem, error) {
+	o := &OggItem{r: r}
+
+	p, err := o.readPacket()
+	if err != nil {
+		return nil, fmt.Errorf("could not read opus header: %v", err)
+	}
+	if err := o.parseHead(p); err != nil {
+		return nil, err
+	}
+
+	p, err = o.readPacket()
+	if err != nil {
+		return nil, fmt.Errorf("could not read opus tags: %v", err)
+	}
+	if err := o.parseTags(p); err != nil {
+		return nil, err
+	}
+	if len(o.packets) != 0 || o.partial != nil {
+		return nil, fmt.Errorf("%v: audio on the page of the opus tags", ErrInvalidOgg)
+	}
+
+	if o.start, err = r.Seek(0, io.SeekCurrent); err != nil {
+		return nil, err
+	}
+	if o.duration, err = o.readDuration(); err != nil {
+		return nil, err
+	}
+	if _, err := r.Seek(o.start, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+func (o *OggItem) parseHead(p []byte) error {
+	if len(p) < 19 || string(p[:8]) != "OpusHead" {
+		return ErrNotOpus
+	}
+
+	o.head = OpusHead{
+		Version:         p[8],
+		Channels:        p[9],
+		PreSkip:         binary.LittleEndian.Uint16(p[10:]),
+		InputSampleRate: binary.LittleEndian.Uint32(p[12:]),
+		OutputGain:      int16(binary.LittleEndian.Uint16(p[16:])),
+		MappingFamily:   p[18],
+	}
+	switch {
+	case o.head.Version>>4 != 0:
+		return fmt.Errorf("%v: version %d", ErrUnsupportedOpus, o.head.Version)
+	case o.head.Channels != Channels:
+		return fmt.Errorf("%v: %d channels, discord needs %d", ErrUnsupportedOpus, o.head.Channels, Channels)
+	case o.head.MappingFamily != 0:
+		return fmt.Errorf("%v: channel mapping family %d", ErrUnsupportedOpus, o.head.MappingFamily)
+	}
+	return nil
+}
+
+// parseTags reads the user comments of the OpusTags header
+func (o *OggItem) parseTags(p []byte) error {
+	if len(p) < 16 || string(p[:8]) != "OpusTags" {
+		return fmt.Errorf("%v: no opus tags", ErrInvalidOgg)
+	}
+	invalid := fmt.Errorf("%v: invalid opus tags", ErrInvalidOgg)
+
+	p = p[8:]
+	n := binary.LittleEndian.Uint32(p)
+	if uint64(len(p)) < 8+uint64(n) {
+		return invalid
+	}
+	p = p[4+n:]
+	count := binary.LittleEndian.Uint32(p)
+	p = p[4:]
+
+	o.tags = make(map[string]string)
+	for i := uint32(0); i < count; i++ {
+		if len(p) < 4 {
+			return invalid
+		}
+		n := binary.LittleEndian.Uint32(p)
+		if uint64(len(p)) < 4+uint64(n) {
+			return invalid
+		}
+		c := string(p[4 : 4+n])
+		p = p[4+n:]
+
+		if i := strings.Index(c, "="); i > 0 {
+			o.tags[strings.ToUpper(c[:i])] = c[i+1:]
+		}
+	}
+	return nil
+}
+
+// readDuration reads the granule position of the last page of the stream
+func (o *OggItem) readDuration() (time.Duration, error) {
+	end, err := o.r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+	from := end - oggMaxPageSize
+	if from < o.start {
+		from = o.start
+	}
+	if _, err := o.r.Seek(from, io.SeekStart); err != nil {
+		return 0, err
+	}
+	b := make([]byte, end-from)
+	if _, err := io.ReadFull(o.r, b); err != nil {
+		return 0, err
+	}
+
+	// "OggS" may also be part of the audio, only pages with a valid checksum are used
+	for i := bytes.LastIndex(b, []byte("OggS")); i >= 0; i = bytes.LastIndex(b[:i], []byte("OggS")) {
+		pg, _, err := readOggPage(bytes.NewReader(b[i:]))
+		if err != nil || pg.serial != o.serial || pg.granule == oggNoGranule {
+			continue
+		}
+
+		samples := int64(pg.granule) - int64(o.head.PreSkip)
+		if samples < 0 {
+			samples = 0
+		}
+		return time.Duration(samples) * time.Second / SampleRate, nil
+	}
+	return 0, nil
+}
+
+// readPage reads the next page of the stream and checks its checksum
+func (o *OggItem) readPage() (oggPage, []byte, error) {
+	return readOggPage(o.r)
+}
+
+// readOggPage reads a page from r and checks its checksum
+func readOggPage(r io.Reader) (oggPage, []byte, error) {
+	var h [oggHeaderSize]byte
+	if _, err := io.ReadFull(r, h[:]); err != nil {
+		return oggPage{}, nil, err
+	}
+	if string(h[:4]) != "OggS" || h[4] != 0 {
+		return oggPage{}, nil, fmt.Errorf("%v: no page header", ErrInvalidOgg)
+	}
+
+	pg := oggPage{
+		flags:    h[5],
+		granule:  binary.LittleEndian.Uint64(h[6:]),
+		serial:   binary.LittleEndian.Uint32(h[14:]),
+		segments: make([]byte, h[26]),
+	}
+	if _, err := io.ReadFull(r, pg.segments); err != nil {
+		return oggPage{}, nil, unexpectedEOF(err)
+	}
+	size := 0
+	for _, s := range pg.segments {
+		size += int(s)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return oggPage{}, nil, unexpectedEOF(err)
+	}
+
+	sum := binary.LittleEndian.Uint32(h[22:])
+	for i := 22; i < 26; i++ {
+		h[i] = 0
+	}
+	crc := oggCRC(0, h[:])
+	crc = oggCRC(crc, pg.segments)
+	crc = oggCRC(crc, data)
+	if crc != sum {
+		return oggPage{}, nil, ErrOggChecksum
+	}
+
+	return pg, data, nil
+}
+
+// readPacket returns the next packet of the opus stream
+func (o *OggItem) readPacket() ([]byte, error) {
+	for len(o.packets) == 0 {
+		pg, data, err := o.readPage()
+		if err == io.EOF && o.partial != nil {
+			return nil, fmt.Errorf("%v: stream ends in a packet", ErrInvalidOgg)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if !o.hasSerial {
+			o.serial, o.hasSerial = pg.serial, true
+		}
+		if pg.serial != o.serial {
+			continue
+		}
+		if pg.flags&oggContinued == 0 {
+			o.partial = nil
+		}
+
+		p := o.partial
+		for _, s := range pg.segments {
+			p = append(p, data[:s]...)
+			data = data[s:]
+			if s < 255 {
+				o.packets = append(o.packets, p)
+				p = nil
+			}
+		}
+		o.partial = p
+	}
+
+	p := o.packets[0]
+	o.packets = o.packets[1:]
+	return p, nil
+}
+
+// OpusFrame returns the next 20ms opus packet
+func (o *OggItem) OpusFrame() ([]byte, error) {
+	if o.err != nil {
+		return nil, o.err
+	}
+
+	p, err := o.readPacket()
+	if err != nil {
+		return nil, err
+	}
+	if err := checkFrame(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ResetPlayback seeks back to the first audio packet
+func (o *OggItem) ResetPlayback() {
+	o.packets = nil
+	o.partial = nil
+	_, o.err = o.r.Seek(o.start, io.SeekStart)
+}
+
+// Head returns the identification header of the stream
+func (o *OggItem) Head() OpusHead {
+	return o.head
+}
+
+// Tags returns the user comments of the stream with upper case keys
+func (o *OggItem) Tags() map[string]string {
+	return o.tags
+}
+
+// Duration returns the duration of the stream from the granule position of its last page
+func (o *OggItem) Duration() time.Duration {
+	return o.duration
+}
+
+// Metadata returns the title and artist tags and the duration of the stream
+func (o *OggItem) Metadata() fuzzy.Metadata {
+	return fuzzy.Metadata{
+		Title:    o.tags["TITLE"],
+		Artist:   o.tags["ARTIST"],
+		Duration: o.duration,
+	}
+}
+
+// Close closes the reader of the item if it is an io.Closer
+func (o *OggItem) Close() error {
+	if c, ok := o.r.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// oggCRCTable is the lookup table of the ogg checksum which uses polynomial 0x04c11db7 without reflection
+var oggCRCTable = func() [256]uint32 {
+	var t [256]uint32
+	for i := range t {
+		r := uint32(i) << 24
+		for j := 0; j < 8; j++ {
+			if r&0x80000000 != 0 {
+				r = r<<1 ^ 0x04c11db7
+			} else {
+				r <<= 1
+			}
+		}
+		t[i] = r
+	}
+	return t
+}()
+
+func oggCRC(crc uint32, b []byte) uint32 {
+	for _, c := range b {
+		crc = crc<<8 ^ oggCRCTable[byte(crc>>24)^c]
+	}
+	return crc
+}
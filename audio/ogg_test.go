@@ -0,0 +1,232 @@
+package audio_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fvdveen/fuzzy/audio"
+)
+
+// crcTable is the table of the ogg checksum
+var crcTable = func() [256]uint32 {
+	var t [256]uint32
+	for i := range t {
+		r := uint32(i) << 24
+		for j := 0; j < 8; j++ {
+			if r&0x80000000 != 0 {
+				r = r<<1 ^ 0x04c11db7
+			} else {
+				r <<= 1
+			}
+		}
+		t[i] = r
+	}
+	return t
+}()
+
+// oggWriter writes packets as ogg pages
+type oggWriter struct {
+	buf     bytes.Buffer
+	seq     uint32
+	granule uint64
+	flags   byte
+}
+
+// page writes the packets in a page, the last packet continues on the next page when open is set
+func (w *oggWriter) page(granule uint64, open bool, packets ...[]byte) {
+	var segs, data []byte
+	for i, p := range packets {
+		data = append(data, p...)
+		for ; len(p) >= 255; p = p[255:] {
+			segs = append(segs, 255)
+		}
+		if !open || i != len(packets)-1 {
+			segs = append(segs, byte(len(p)))
+		} else if len(p) > 0 {
+			panic("an open packet must be a multiple of 255 bytes")
+		}
+	}
+
+	h := make([]byte, 27)
+	copy(h, "OggS")
+	h[5] = w.flags
+	binary.LittleEndian.PutUint64(h[6:], granule)
+	binary.LittleEndian.PutUint32(h[14:], 1)
+	binary.LittleEndian.PutUint32(h[18:], w.seq)
+	h[26] = byte(len(segs))
+
+	var crc uint32
+	for _, b := range [][]byte{h, segs, data} {
+		for _, c := range b {
+			crc = crc<<8 ^ crcTable[byte(crc>>24)^c]
+		}
+	}
+	binary.LittleEndian.PutUint32(h[22:], crc)
+
+	w.buf.Write(h)
+	w.buf.Write(segs)
+	w.buf.Write(data)
+	w.seq++
+	w.flags = 0
+	if open {
+		w.flags = 1
+	}
+}
+
+func opusHead(channels byte, rate uint32) []byte {
+	h := make([]byte, 19)
+	copy(h, "OpusHead")
+	h[8] = 1
+	h[9] = channels
+	binary.LittleEndian.PutUint16(h[10:], 312)
+	binary.LittleEndian.PutUint32(h[12:], rate)
+	return h
+}
+
+func opusTags(comments ...string) []byte {
+	var b bytes.Buffer
+	b.WriteString("OpusTags")
+	_ = binary.Write(&b, binary.LittleEndian, uint32(5))
+	b.WriteString("fuzzy")
+	_ = binary.Write(&b, binary.LittleEndian, uint32(len(comments)))
+	for _, c := range comments {
+		_ = binary.Write(&b, binary.LittleEndian, uint32(len(c)))
+		b.WriteString(c)
+	}
+	return b.Bytes()
+}
+
+// frame is a 20ms stereo CELT packet of n bytes
+func frame(n int, fill byte) []byte {
+	p := bytes.Repeat([]byte{fill}, n)
+	p[0] = 31<<3 | 0x04
+	return p
+}
+
+// stream writes an ogg opus stream with the head and the frames over two pages
+// the second frame is 300 bytes long and continues on the next page
+func stream(head []byte, frames ...[]byte) []byte {
+	w := &oggWriter{flags: 2}
+	w.page(0, false, head)
+	w.page(0, false, opusTags("title=Song", "ARTIST=Band", "invalid"))
+	if len(frames) > 1 {
+		big := frames[1]
+		w.page(oggNoGranule, true, frames[0], big[:255])
+		w.page(uint64(312+len(frames)*960), false, append([][]byte{big[255:]}, frames[2:]...)...)
+	} else {
+		w.page(uint64(312+len(frames)*960), false, frames...)
+	}
+	return w.buf.Bytes()
+}
+
+const oggNoGranule = ^uint64(0)
+
+func TestOggItem(t *testing.T) {
+	frames := [][]byte{frame(10, 1), frame(300, 2), frame(20, 3)}
+	o, err := audio.NewOggItem(bytes.NewReader(stream(opusHead(2, 44100), frames...)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d := o.Duration(); d != 60*time.Millisecond {
+		t.Errorf("expected a duration of 60ms got %v", d)
+	}
+	if md := o.Metadata(); md.Title != "Song" || md.Artist != "Band" || md.Duration != o.Duration() {
+		t.Errorf("expected the metadata from the tags got %+v", md)
+	}
+	if h := o.Head(); h.Channels != 2 || h.PreSkip != 312 || h.InputSampleRate != 44100 {
+		t.Errorf("expected the opus head to be read got %+v", h)
+	}
+
+	for i := 0; i < 2; i++ {
+		var got [][]byte
+		for {
+			f, err := o.OpusFrame()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, f)
+		}
+		if !reflect.DeepEqual(got, frames) {
+			t.Fatalf("expected the frames %v got %v", frames, got)
+		}
+		o.ResetPlayback()
+	}
+}
+
+func TestOggItemFakePage(t *testing.T) {
+	// the last frame holds what looks like a page header with a later granule position
+	fake := frame(40, 4)
+	copy(fake[1:], "OggS")
+	binary.LittleEndian.PutUint64(fake[7:], 312+10*48000)
+	binary.LittleEndian.PutUint32(fake[15:], 1)
+
+	o, err := audio.NewOggItem(bytes.NewReader(stream(opusHead(2, 48000), frame(10, 1), frame(300, 2), fake)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := o.Duration(); d != 60*time.Millisecond {
+		t.Errorf("expected a duration of 60ms got %v", d)
+	}
+}
+
+func TestOggItemInvalid(t *testing.T) {
+	corrupt := stream(opusHead(2, 48000), frame(10, 1))
+	corrupt[len(corrupt)-1]++
+
+	tests := []struct {
+		name   string
+		stream []byte
+		err    string
+	}{
+		{"mono", stream(opusHead(1, 48000), frame(10, 1)), "1 channels"},
+		{"sample rate", stream(opusHead(2, 44100), frame(10, 1)), ""},
+		{"unknown sample rate", stream(opusHead(2, 0), frame(10, 1)), ""},
+		{"not opus", stream([]byte("OpusHeaf and more bytes"), frame(10, 1)), audio.ErrNotOpus.Error()},
+		{"checksum", corrupt, audio.ErrOggChecksum.Error()},
+		{"60ms frame", stream(opusHead(2, 48000), []byte{3 << 3, 0}), audio.ErrFrameDuration.Error()},
+		{"not ogg", []byte(strings.Repeat("x", 100)), audio.ErrInvalidOgg.Error()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o, err := audio.NewOggItem(bytes.NewReader(tt.stream))
+			if err == nil {
+				_, err = o.OpusFrame()
+			}
+			if tt.err == "" && err != nil || tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+				t.Fatalf("expected an error containing %q got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestPacketDuration(t *testing.T) {
+	tests := []struct {
+		packet []byte
+		exp    time.Duration
+	}{
+		{[]byte{31<<3 | 0x04}, 20 * time.Millisecond},
+		{[]byte{16 << 3}, 2500 * time.Microsecond},
+		{[]byte{3 << 3}, 60 * time.Millisecond},
+		{[]byte{13<<3 | 1}, 40 * time.Millisecond},
+		{[]byte{18<<3 | 3, 4}, 40 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		if d, err := audio.PacketDuration(tt.packet); err != nil || d != tt.exp {
+			t.Errorf("expected %v for %08b got %v, %v", tt.exp, tt.packet[0], d, err)
+		}
+	}
+	if _, err := audio.PacketDuration(nil); err == nil {
+		t.Error("expected an error for an empty packet")
+	}
+}
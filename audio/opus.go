@@ -0,0 +1,65 @@
+// Package audio has fuzzy.VoiceItems which play opus audio from ogg and DCA files
+package audio
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fvdveen/fuzzy"
+)
+
+const (
+	// SampleRate is the sample rate of the opus audio sent to discord
+	SampleRate = 48000
+	// Channels is the amount of channels of the opus audio sent to discord
+	Channels = 2
+	// FrameDuration is the duration of the opus packets sent to discord
+	FrameDuration = fuzzy.FrameDuration
+)
+
+// frameSizes are the durations of the frames of each opus configuration in microseconds
+var frameSizes = [32]time.Duration{
+	// SILK
+	10000, 20000, 40000, 60000,
+	10000, 20000, 40000, 60000,
+	10000, 20000, 40000, 60000,
+	// hybrid
+	10000, 20000,
+	10000, 20000,
+	// CELT
+	2500, 5000, 10000, 20000,
+	2500, 5000, 10000, 20000,
+	2500, 5000, 10000, 20000,
+	2500, 5000, 10000, 20000,
+}
+
+// PacketDuration returns the duration of the opus packet from its TOC byte
+func PacketDuration(p []byte) (time.Duration, error) {
+	if len(p) == 0 {
+		return 0, fmt.Errorf("%v: empty packet", ErrInvalidPacket)
+	}
+
+	frames := 1
+	switch p[0] & 0x3 {
+	case 1, 2:
+		frames = 2
+	case 3:
+		if len(p) < 2 {
+			return 0, fmt.Errorf("%v: no frame count", ErrInvalidPacket)
+		}
+		frames = int(p[1] & 0x3f)
+	}
+	return time.Duration(frames) * frameSizes[p[0]>>3] * time.Microsecond, nil
+}
+
+// checkFrame returns an error when the packet is not FrameDuration long
+func checkFrame(p []byte) error {
+	d, err := PacketDuration(p)
+	if err != nil {
+		return err
+	}
+	if d != FrameDuration {
+		return fmt.Errorf("%v: packet is %v", ErrFrameDuration, d)
+	}
+	return nil
+}
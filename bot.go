@@ -2,42 +2,77 @@ package fuzzy
 
 import (
 	"sync"
+	"sync/atomic"
 
 	"github.com/bwmarrin/discordgo"
 )
 
 // Bot represents a discord bot
 type Bot struct {
-	conf *Config
+	// conf holds the *Config, it is replaced when the config is reloaded
+	conf atomic.Value
 	sess *discordgo.Session
+	// transport is used for everything but the gateway, the console replaces it while it runs
+	transport   Transport
+	transportMu sync.RWMutex
 
 	commands      []Command
+	commandsMu    sync.RWMutex
+	commandState  CommandStateStore
+	store         Store
+	settings      *settings
+	modules       map[string]*loadedModule
+	modulesMu     sync.RWMutex
+	reloadMu      sync.Mutex
 	voiceHandlers map[string]VoiceHandler
 	voiceMu       sync.RWMutex
 	generator     *Generator
 	middleware    MiddlewareChain
+	checks        []Check
+	theme         Theme
+	events        *EventBus
+	scheduler     *scheduler
+
+	replies     []*replyWaiter
+	repliesMu   sync.Mutex
+	reactions   []*reactionWaiter
+	reactionsMu sync.Mutex
+	activity    activity
 }
 
 // New creates a new bot
 func New(opts ...OptionFunc) (*Bot, error) {
 	b := &Bot{
-		conf:          &Config{},
+		modules:       make(map[string]*loadedModule),
 		voiceHandlers: make(map[string]VoiceHandler),
 		commands:      []Command{},
+		store:         NewMemoryStore(),
+		settings:      newSettings(),
 		generator:     DefaultGenerator(),
 		middleware:    NewMiddlewareChain(),
+		theme:         DefaultTheme(),
+		scheduler:     newScheduler(),
 	}
 
+	b.conf.Store(&Config{})
 	for _, opt := range opts {
 		opt(b)
 	}
+	if b.commandState == nil {
+		b.commandState = NewCommandStateStore(Namespace(b.store, "commands"))
+	}
+	if err := b.RegisterSetting(b.defaultSettings()...); err != nil {
+		return nil, err
+	}
 
-	sess, err := discordgo.New("Bot " + b.conf.Token)
+	sess, err := discordgo.New("Bot " + b.Config().Token)
 	if err != nil {
 		return nil, err
 	}
 	b.sess = sess
+	b.transport = b.generator.transportGenerator(sess)
 
+	b.events = NewEventBus(b)
 	b.initHandlers()
 
 	return b, nil
@@ -45,46 +80,151 @@ func New(opts ...OptionFunc) (*Bot, error) {
 
 // Open opens the discord session
 func (b *Bot) Open() error {
-	return b.sess.Open()
+	if err := b.sess.Open(); err != nil {
+		return err
+	}
+	b.scheduler.start()
+
+	return nil
+}
+
+// RunTool runs the bot for fuzzy-docs or fuzzy-console when one of them started the bot, it reports false otherwise
+// the tools set CatalogEnv and ConsoleEnv, Open ignores them so the bot has to opt in by calling RunTool before Open
+//
+//	if ok, err := b.RunTool(); ok {
+//		return err
+//	}
+func (b *Bot) RunTool() (bool, error) {
+	if ok, err := b.writeCatalog(); ok {
+		return true, err
+	}
+	return b.runConsole()
 }
 
 // Close closes the discord session
 func (b *Bot) Close() error {
+	b.scheduler.stop()
 	for _, vh := range b.voiceHandlers {
 		vh.Stop()
 	}
 	return b.sess.Close()
 }
 
+// Transport returns the transport the bot uses to talk to discord
+func (b *Bot) Transport() Transport {
+	b.transportMu.RLock()
+	defer b.transportMu.RUnlock()
+
+	return b.transport
+}
+
+// setTransport replaces the transport and returns the previous one
+func (b *Bot) setTransport(t Transport) Transport {
+	b.transportMu.Lock()
+	defer b.transportMu.Unlock()
+
+	prev := b.transport
+	b.transport = t
+	return prev
+}
+
 // Session returns the discordgo session of the bot
 func (b *Bot) Session() *discordgo.Session {
 	return b.sess
 }
 
 // Config gives the bot's config
+// the config must not be changed, use Reload to change it
 func (b *Bot) Config() *Config {
-	return b.conf
+	return b.conf.Load().(*Config)
 }
 
 // RegisterCommand registers a command with the bot
 func (b *Bot) RegisterCommand(cs ...Command) error {
+	b.commandsMu.Lock()
+	defer b.commandsMu.Unlock()
+
+	// all names are checked first so a duplicate does not leave the commands half registered
+	names := map[string]bool{}
+	for _, com := range b.commands {
+		for _, n := range commandNames(com) {
+			names[n] = true
+		}
+	}
 	for _, c := range cs {
-		for _, com := range b.commands {
-			if c.Name() == com.Name() {
+		ns := commandNames(c)
+		for _, n := range ns {
+			if names[n] {
 				return ErrDuplicateCommand
 			}
 		}
-		b.commands = append(b.commands, c)
+		for _, n := range ns {
+			names[n] = true
+		}
 	}
+	b.commands = append(b.commands, cs...)
 
 	return nil
 }
 
-// RegisterHandler adds a discordgo handler to the bot
-func (b *Bot) RegisterHandler(hs ...interface{}) {
+// UnregisterCommand removes the commands with the given names from the bot
+func (b *Bot) UnregisterCommand(names ...string) error {
+	b.commandsMu.Lock()
+	defer b.commandsMu.Unlock()
+
+	// all names are checked first so an unknown name does not leave the commands half removed
+	for _, n := range names {
+		if b.commandIndex(n) < 0 {
+			return ErrUnknownCommand
+		}
+	}
+	for _, n := range names {
+		if i := b.commandIndex(n); i >= 0 {
+			b.commands = append(b.commands[:i:i], b.commands[i+1:]...)
+		}
+	}
+
+	return nil
+}
+
+// Command returns the command with the given name or alias
+func (b *Bot) Command(name string) (Command, error) {
+	b.commandsMu.RLock()
+	defer b.commandsMu.RUnlock()
+
+	i := b.commandIndex(name)
+	if i < 0 {
+		return nil, ErrUnknownCommand
+	}
+	return b.commands[i], nil
+}
+
+// commandIndex finds the index of the command with the name or alias, it must be called with commandsMu held
+func (b *Bot) commandIndex(name string) int {
+	for i, com := range b.commands {
+		for _, n := range commandNames(com) {
+			if n == name {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// RegisterHandler subscribes the handlers to the bot's event bus, see EventBus.Subscribe
+// no handlers are subscribed when one of them is invalid
+func (b *Bot) RegisterHandler(hs ...interface{}) error {
+	for _, h := range hs {
+		if _, _, err := eventHandlerFor(h); err != nil {
+			return err
+		}
+	}
 	for _, h := range hs {
-		b.sess.AddHandler(h)
+		if _, err := b.events.Subscribe(h); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
 // UseMiddleware will call the middleware with the context before command handlers are called
@@ -94,6 +234,9 @@ func (b *Bot) UseMiddleware(ms ...Middleware) {
 
 // Commands returns a copy of all the bot's commands
 func (b *Bot) Commands() []Command {
+	b.commandsMu.RLock()
+	defer b.commandsMu.RUnlock()
+
 	cs := append(([]Command)(nil), b.commands...)
 	return cs
 }
@@ -119,7 +262,7 @@ func (b *Bot) PlaySound(gid, voiceChanID, textChanID string, vi VoiceItem) {
 
 	vh, ok := b.voiceHandlers[gid]
 	if !ok {
-		b.voiceHandlers[gid] = b.generator.voiceHandlerGenerator(b.sess, b, gid, voiceChanID, textChanID, vi)
+		b.voiceHandlers[gid] = b.generator.voiceHandlerGenerator(b.Transport(), b, gid, voiceChanID, textChanID, vi)
 		return
 	}
 	vh.Play(vi)
@@ -127,16 +270,7 @@ func (b *Bot) PlaySound(gid, voiceChanID, textChanID string, vi VoiceItem) {
 
 // GetVoiceState returns the voicestate of the given userID
 func (b *Bot) GetVoiceState(gid, userID string) (*discordgo.VoiceState, error) {
-	g, err := b.sess.State.Guild(gid)
-	if err != nil {
-		return nil, err
-	}
-	for _, vs := range g.VoiceStates {
-		if vs.UserID == userID {
-			return vs, nil
-		}
-	}
-	return nil, ErrUnknownVoiceState
+	return b.Transport().VoiceState(gid, userID)
 }
 
 // DeleteVoiceHandler will remove the voice handler from the bot
@@ -151,3 +285,13 @@ func (b *Bot) DeleteVoiceHandler(gid string) {
 func (b *Bot) Generator() *Generator {
 	return b.generator
 }
+
+// Theme returns the colors used for the bot's embeds
+func (b *Bot) Theme() Theme {
+	return b.theme
+}
+
+// NewEmbed creates an embed builder with the bot's primary color
+func (b *Bot) NewEmbed() *EmbedBuilder {
+	return NewEmbed().SetColor(b.theme.Primary)
+}
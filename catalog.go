@@ -0,0 +1,191 @@
+package fuzzy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// CatalogEnv is the environment variable used by fuzzy-docs
+// when it is set Bot.RunTool writes the command catalog as JSON to the path it holds
+const CatalogEnv = "FUZZY_CATALOG"
+
+// Catalog describes all the commands of a bot
+type Catalog struct {
+	Prefix   string        `json:"prefix"`
+	Commands []CommandInfo `json:"commands"`
+}
+
+// CommandInfo describes a single command in a Catalog
+type CommandInfo struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Category    string      `json:"category,omitempty"`
+	Usage       string      `json:"usage,omitempty"`
+	Aliases     []string    `json:"aliases,omitempty"`
+	Examples    []string    `json:"examples,omitempty"`
+	Parameters  []Parameter `json:"parameters,omitempty"`
+	Permissions []string    `json:"permissions,omitempty"`
+	Hidden      bool        `json:"hidden,omitempty"`
+}
+
+// permissionNames are the names of the permissions shown in the catalog
+var permissionNames = []struct {
+	perm int64
+	name string
+}{
+	{discordgo.PermissionAdministrator, "Administrator"},
+	{discordgo.PermissionManageServer, "Manage Server"},
+	{discordgo.PermissionManageRoles, "Manage Roles"},
+	{discordgo.PermissionManageChannels, "Manage Channels"},
+	{discordgo.PermissionKickMembers, "Kick Members"},
+	{discordgo.PermissionBanMembers, "Ban Members"},
+	{discordgo.PermissionManageMessages, "Manage Messages"},
+	{discordgo.PermissionMentionEveryone, "Mention Everyone"},
+	{discordgo.PermissionEmbedLinks, "Embed Links"},
+	{discordgo.PermissionAttachFiles, "Attach Files"},
+	{discordgo.PermissionAddReactions, "Add Reactions"},
+	{discordgo.PermissionSendMessages, "Send Messages"},
+	{discordgo.PermissionVoiceConnect, "Connect"},
+	{discordgo.PermissionVoiceSpeak, "Speak"},
+}
+
+// PermissionNames gives the names of the permissions
+func PermissionNames(perms int64) []string {
+	var ns []string
+	for _, p := range permissionNames {
+		if perms&p.perm == p.perm {
+			ns = append(ns, p.name)
+			perms &^= p.perm
+		}
+	}
+	if perms != 0 {
+		ns = append(ns, fmt.Sprintf("0x%x", perms))
+	}
+	return ns
+}
+
+// Catalog describes all the commands registered with the bot
+func (b *Bot) Catalog() *Catalog {
+	c := &Catalog{Prefix: b.Config().Prefix}
+	for _, com := range b.Commands() {
+		d := Details(com)
+		c.Commands = append(c.Commands, CommandInfo{
+			Name:        com.Name(),
+			Description: com.Description(),
+			Category:    d.Category,
+			Usage:       d.Usage,
+			Aliases:     d.Aliases,
+			Examples:    d.Examples,
+			Parameters:  d.Parameters,
+			Permissions: PermissionNames(d.Permissions),
+			Hidden:      d.Hidden,
+		})
+	}
+	return c
+}
+
+// writeCatalog writes the catalog to the path in CatalogEnv
+// it returns false if the environment variable is not set
+func (b *Bot) writeCatalog() (bool, error) {
+	path := os.Getenv(CatalogEnv)
+	if path == "" {
+		return false, nil
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return true, fmt.Errorf("could not create catalog file: %v", err)
+	}
+
+	if err := b.Catalog().WriteJSON(f); err != nil {
+		f.Close()
+		return true, fmt.Errorf("could not write catalog: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		return true, fmt.Errorf("could not write catalog: %v", err)
+	}
+	return true, nil
+}
+
+// WriteJSON writes the catalog as indented JSON
+func (c *Catalog) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(c)
+}
+
+// WriteMarkdown writes the visible commands of the catalog as a markdown document
+// the commands are grouped by category
+func (c *Catalog) WriteMarkdown(w io.Writer) error {
+	cats := map[string][]CommandInfo{}
+	for _, com := range c.Commands {
+		if com.Hidden {
+			continue
+		}
+		cat := com.Category
+		if cat == "" {
+			cat = defaultCategory
+		}
+		cats[cat] = append(cats[cat], com)
+	}
+
+	names := make([]string, 0, len(cats))
+	for n := range cats {
+		if n != defaultCategory {
+			names = append(names, n)
+		}
+	}
+	sort.Strings(names)
+	if _, ok := cats[defaultCategory]; ok {
+		names = append(names, defaultCategory)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# Commands\n")
+	for _, n := range names {
+		fmt.Fprintf(&sb, "\n## %s\n", n)
+		for _, com := range cats[n] {
+			c.writeCommandMarkdown(&sb, com)
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func (c *Catalog) writeCommandMarkdown(sb *strings.Builder, com CommandInfo) {
+	fmt.Fprintf(sb, "\n### `%s%s`\n\n", c.Prefix, com.Name)
+	if com.Description != "" {
+		fmt.Fprintf(sb, "%s\n\n", com.Description)
+	}
+	fmt.Fprintf(sb, "**Usage:** `%s`\n", strings.TrimSpace(c.Prefix+com.Name+" "+com.Usage))
+
+	if len(com.Aliases) > 0 {
+		fmt.Fprintf(sb, "\n**Aliases:** `%s`\n", strings.Join(com.Aliases, "`, `"))
+	}
+	if len(com.Permissions) > 0 {
+		fmt.Fprintf(sb, "\n**Permissions:** %s\n", strings.Join(com.Permissions, ", "))
+	}
+	if len(com.Parameters) > 0 {
+		sb.WriteString("\n| Parameter | Description | Optional |\n| --- | --- | --- |\n")
+		for _, p := range com.Parameters {
+			opt := "no"
+			if p.Optional {
+				opt = "yes"
+			}
+			fmt.Fprintf(sb, "| `%s` | %s | %s |\n", p.Name, strings.Replace(p.Description, "|", "\\|", -1), opt)
+		}
+	}
+	if len(com.Examples) > 0 {
+		sb.WriteString("\n**Examples:**\n\n")
+		for _, e := range com.Examples {
+			fmt.Fprintf(sb, "- `%s%s`\n", c.Prefix, e)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package fuzzy_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fvdveen/fuzzy"
+)
+
+func TestRunToolCatalog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fuzzy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "catalog.json")
+
+	os.Setenv(fuzzy.CatalogEnv, path)
+	defer os.Unsetenv(fuzzy.CatalogEnv)
+
+	b, err := fuzzy.New(fuzzy.WithConfig(&fuzzy.Config{Token: "token", Prefix: "!", LogLevel: fuzzy.LogError}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.RegisterCommand(fuzzy.NewCommand("ping", "sends pong", func(fuzzy.Context) {})); err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, err := b.RunTool(); !ok || err != nil {
+		t.Fatalf("expected the catalog to be written got %t %v", ok, err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var c fuzzy.Catalog
+	if err := json.Unmarshal(data, &c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Prefix != "!" || len(c.Commands) != 1 || c.Commands[0].Name != "ping" || c.Commands[0].Description != "sends pong" {
+		t.Fatalf("expected the ping command in the catalog got %+v", c)
+	}
+}
@@ -0,0 +1,140 @@
+package fuzzy
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Check decides if a command may be run in the context
+// it returns an error explaining why when it may not
+type Check func(Context) error
+
+// UseCheck adds checks that must pass before any command is run
+func (b *Bot) UseCheck(cs ...Check) {
+	b.checks = append(b.checks, cs...)
+}
+
+// CanRun runs the bot's checks and the checks of the command for the author of ctx
+func (b *Bot) CanRun(ctx Context, com Command) error {
+	if ctx.Command() != com {
+		ctx = b.generator.contextGenerator(ctx, "", ctx.MessageEvent(), b, ctx.Session(), com)
+	}
+
+	if ok, err := b.CommandEnabled(ctx.MessageEvent().GuildID, ctx.MessageEvent().ChannelID, com.Name()); err != nil {
+		return err
+	} else if !ok {
+		return ErrCommandDisabled
+	}
+
+	for _, c := range b.checks {
+		if err := c(ctx); err != nil {
+			return err
+		}
+	}
+	for _, c := range Details(com).Checks {
+		if err := c(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GuildOnly only allows the command to be used in guilds
+func GuildOnly() Check {
+	return func(ctx Context) error {
+		if ctx.MessageEvent().GuildID == "" {
+			return ErrGuildOnly
+		}
+		return nil
+	}
+}
+
+// RequirePermissions only allows users with all the permissions in the channel to use the command
+func RequirePermissions(perms int64) Check {
+	return func(ctx Context) error {
+		p, err := userPermissions(ctx)
+		if err != nil {
+			return fmt.Errorf("could not get permissions: %v", err)
+		}
+		if p&perms != perms {
+			return ErrMissingPermissions
+		}
+		return nil
+	}
+}
+
+// permissionsKey is the context key of the permissionsCache
+type permissionsKey struct{}
+
+// permissionsCache holds the permissions of the author once they are looked up
+type permissionsCache struct {
+	once  sync.Once
+	perms int64
+	err   error
+}
+
+// withPermissionsCache makes the checks run with ctx share a single lookup of the permissions of the author
+func withPermissionsCache(ctx Context) Context {
+	return ctx.WithContext(context.WithValue(ctx, permissionsKey{}, &permissionsCache{}))
+}
+
+// userPermissions returns the permissions of the author in the channel of ctx
+func userPermissions(ctx Context) (int64, error) {
+	lookup := func() (int64, error) {
+		return ctx.Transport().UserChannelPermissions(ctx.MessageEvent().Author.ID, ctx.MessageEvent().ChannelID)
+	}
+	pc, ok := ctx.Value(permissionsKey{}).(*permissionsCache)
+	if !ok {
+		return lookup()
+	}
+	pc.once.Do(func() {
+		pc.perms, pc.err = lookup()
+	})
+	return pc.perms, pc.err
+}
+
+// OwnerOnly only allows the owners of the bot in the config to use the command
+func OwnerOnly() Check {
+	return func(ctx Context) error {
+		if !ctx.Bot().Config().IsOwner(ctx.MessageEvent().Author.ID) {
+			return ErrOwnerOnly
+		}
+		return nil
+	}
+}
+
+// RequireDJ only allows users with the DJ role of the guild or the manage server permission to use the command
+// everyone may use the command when the guild has no DJ role
+func RequireDJ() Check {
+	return func(ctx Context) error {
+		v, err := ctx.GuildSetting(DJRoleSetting)
+		if err != nil {
+			return err
+		}
+		role, _ := v.(string)
+		if role == "" {
+			return nil
+		}
+
+		m := ctx.MessageEvent().Member
+		if m == nil {
+			if m, err = ctx.Transport().GuildMember(ctx.MessageEvent().GuildID, ctx.MessageEvent().Author.ID); err != nil {
+				return fmt.Errorf("could not get member: %v", err)
+			}
+		}
+		for _, r := range m.Roles {
+			if r == role {
+				return nil
+			}
+		}
+
+		if RequirePermissions(discordgo.PermissionManageServer)(ctx) == nil {
+			return nil
+		}
+		return ErrNotDJ
+	}
+}
@@ -0,0 +1,67 @@
+// Command fuzzy-console runs a fuzzy bot in the terminal without connecting to discord
+//
+// it runs the bot's main package with FUZZY_CONSOLE set, which makes Bot.RunTool read
+// messages from stdin and print what the bot does, main has to call Bot.RunTool before Bot.Open
+// and return once it reports that it ran
+//
+//	fuzzy-console -user 42 -voice 5 ./cmd/mybot -- -prefix !
+//
+// lines like ":react <message id> <emoji>" react to the messages of the bot
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/fvdveen/fuzzy"
+)
+
+func main() {
+	user := flag.String("user", "", "The ID of the user sending the messages")
+	guild := flag.String("guild", "", "The ID of the guild the messages are sent in")
+	channel := flag.String("channel", "", "The ID of the channel the messages are sent in")
+	voice := flag.String("voice", "", "The ID of the voice channel the user is in")
+	dm := flag.Bool("dm", false, "Send the messages as direct messages")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] [package] [-- bot arguments]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	pkg := "."
+	args := flag.Args()
+	if len(args) > 0 && args[0] != "--" {
+		pkg = args[0]
+		args = args[1:]
+	}
+	if len(args) > 0 && args[0] == "--" {
+		args = args[1:]
+	}
+
+	opts := []string{"1"}
+	for k, v := range map[string]string{"user": *user, "guild": *guild, "channel": *channel, "voice": *voice} {
+		if v != "" {
+			opts = append(opts, k+"="+v)
+		}
+	}
+	if *dm {
+		opts = append(opts, "guild=")
+	}
+
+	cmd := exec.Command("go", append([]string{"run", pkg}, args...)...)
+	cmd.Env = append(os.Environ(), fuzzy.ConsoleEnv+"="+strings.Join(opts, ","))
+	// the console does not connect to discord so any token will do
+	if os.Getenv(fuzzy.DefaultEnvPrefix+"_TOKEN") == "" {
+		cmd.Env = append(cmd.Env, fuzzy.DefaultEnvPrefix+"_TOKEN=console")
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		log.Fatalf("could not run %s: %v", pkg, err)
+	}
+}
@@ -0,0 +1,108 @@
+// Command fuzzy-docs writes the command catalog of a fuzzy bot as JSON and Markdown
+//
+// it runs the bot's main package with FUZZY_CATALOG set, which makes Bot.RunTool
+// write the registered commands instead of connecting to discord, main has to call Bot.RunTool
+// before Bot.Open and return once it reports that it ran
+//
+//	fuzzy-docs -json docs/commands.json -md docs/commands.md ./cmd/mybot -- -prefix !
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/fvdveen/fuzzy"
+)
+
+func main() {
+	jsonOut := flag.String("json", "commands.json", "The file the JSON catalog is written to, empty to skip")
+	mdOut := flag.String("md", "commands.md", "The file the Markdown catalog is written to, empty to skip")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] [package] [-- bot arguments]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	pkg := "."
+	args := flag.Args()
+	if len(args) > 0 && args[0] != "--" {
+		pkg = args[0]
+		args = args[1:]
+	}
+	if len(args) > 0 && args[0] == "--" {
+		args = args[1:]
+	}
+
+	c, err := loadCatalog(pkg, args)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *jsonOut != "" {
+		if err := writeFile(*jsonOut, c.WriteJSON); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *mdOut != "" {
+		if err := writeFile(*mdOut, c.WriteMarkdown); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// loadCatalog runs the bot package and reads the catalog it writes
+func loadCatalog(pkg string, args []string) (*fuzzy.Catalog, error) {
+	dir, err := ioutil.TempDir("", "fuzzy-docs")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "catalog.json")
+
+	cmd := exec.Command("go", append([]string{"run", pkg}, args...)...)
+	cmd.Env = append(os.Environ(), fuzzy.CatalogEnv+"="+path)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	// the bot may exit with an error after the catalog is written, the catalog is still used then
+	runErr := cmd.Run()
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if runErr != nil {
+			return nil, fmt.Errorf("could not run %s: %v", pkg, runErr)
+		}
+		return nil, fmt.Errorf("%s did not write a catalog, does it call Bot.RunTool", pkg)
+	}
+	if runErr != nil {
+		log.Printf("%s exited with an error after writing the catalog: %v", pkg, runErr)
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read catalog: %v", err)
+	}
+
+	c := &fuzzy.Catalog{}
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, fmt.Errorf("could not decode catalog: %v", err)
+	}
+	return c, nil
+}
+
+func writeFile(path string, write func(w io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,196 @@
+package fuzzy
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// CommandStateStore keeps which commands are disabled in which guilds and channels
+// the scope is either a guild ID or a channel ID
+type CommandStateStore interface {
+	Disabled(scopeID, command string) (bool, error)
+	SetDisabled(scopeID, command string, disabled bool) error
+}
+
+type memoryCommandStateStore struct {
+	mu       sync.RWMutex
+	disabled map[string]map[string]bool
+}
+
+// NewMemoryCommandStateStore creates a CommandStateStore which keeps the state in memory
+func NewMemoryCommandStateStore() CommandStateStore {
+	return &memoryCommandStateStore{
+		disabled: make(map[string]map[string]bool),
+	}
+}
+
+func (s *memoryCommandStateStore) Disabled(scopeID, command string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.disabled[scopeID][command], nil
+}
+
+func (s *memoryCommandStateStore) SetDisabled(scopeID, command string, disabled bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !disabled {
+		delete(s.disabled[scopeID], command)
+		return nil
+	}
+
+	if s.disabled[scopeID] == nil {
+		s.disabled[scopeID] = make(map[string]bool)
+	}
+	s.disabled[scopeID][command] = true
+	return nil
+}
+
+type storeCommandStateStore struct {
+	s Store
+}
+
+// NewCommandStateStore creates a CommandStateStore which keeps the state in s
+func NewCommandStateStore(s Store) CommandStateStore {
+	return &storeCommandStateStore{s: s}
+}
+
+func (s *storeCommandStateStore) Disabled(scopeID, command string) (bool, error) {
+	_, err := s.s.Get(scopeID + "/" + command)
+	if err == ErrNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *storeCommandStateStore) SetDisabled(scopeID, command string, disabled bool) error {
+	if !disabled {
+		return s.s.Delete(scopeID + "/" + command)
+	}
+	return s.s.Set(scopeID+"/"+command, []byte("disabled"), 0)
+}
+
+// EnableCommand enables the command in the guild or channel
+func (b *Bot) EnableCommand(scopeID, name string) error {
+	com, err := b.Command(name)
+	if err != nil {
+		return err
+	}
+	return b.commandState.SetDisabled(scopeID, com.Name(), false)
+}
+
+// DisableCommand disables the command in the guild or channel
+func (b *Bot) DisableCommand(scopeID, name string) error {
+	com, err := b.Command(name)
+	if err != nil {
+		return err
+	}
+	return b.commandState.SetDisabled(scopeID, com.Name(), true)
+}
+
+// CommandEnabled reports if the command is enabled in the channel of the guild
+// commands in the disabled commands of the config and the guild's setting are also disabled
+// a command disabled in the guild stays disabled in all its channels
+func (b *Bot) CommandEnabled(guildID, channelID, name string) (bool, error) {
+	for _, n := range b.Config().DisabledCommands {
+		if n == name {
+			return false, nil
+		}
+	}
+	if guildID != "" {
+		d, err := b.guildCommandDisabled(guildID, name)
+		if err != nil {
+			return false, fmt.Errorf("could not get command state: %v", err)
+		}
+		if d {
+			return false, nil
+		}
+	}
+
+	for _, id := range []string{guildID, channelID} {
+		if id == "" {
+			continue
+		}
+		d, err := b.commandState.Disabled(id, name)
+		if err != nil {
+			return false, fmt.Errorf("could not get command state: %v", err)
+		}
+		if d {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// CommandStateCommand is a standard command to enable and disable commands in a guild or channel
+// it can only be used by users with the manage server permission
+func CommandStateCommand() Command {
+	const name = "command"
+
+	return NewCommand(name, "Enables or disables commands", func(ctx Context) {
+		args := strings.Fields(ctx.Message())
+		if len(args) < 2 || (args[0] != "enable" && args[0] != "disable") {
+			_, _ = ctx.SendError(fmt.Errorf("usage: %s%s %s", ctx.Bot().Prefix(ctx.MessageEvent().GuildID), name, Details(ctx.Command()).Usage))
+			return
+		}
+
+		com, err := ctx.Bot().Command(args[1])
+		if err != nil {
+			_, _ = ctx.SendError(fmt.Errorf("unknown command: %s", args[1]))
+			return
+		}
+		if com.Name() == name {
+			_, _ = ctx.SendError(fmt.Errorf("the %s command can not be disabled", name))
+			return
+		}
+
+		scope, where := ctx.MessageEvent().GuildID, "this server"
+		if len(args) > 2 && args[2] == "here" {
+			scope, where = ctx.MessageEvent().ChannelID, "this channel"
+		}
+
+		// a channel can only narrow what the server allows
+		if scope != ctx.MessageEvent().GuildID && args[0] == "enable" {
+			ok, err := ctx.Bot().CommandEnabled(ctx.MessageEvent().GuildID, "", com.Name())
+			if err != nil {
+				ctx.Logger().Errorf("Could not get command state: %v", err)
+				_, _ = ctx.SendError(fmt.Errorf("could not enable %s", com.Name()))
+				return
+			}
+			if !ok {
+				_, _ = ctx.SendError(fmt.Errorf("%s is disabled in this server, enable it in the server before enabling it in a channel", com.Name()))
+				return
+			}
+		}
+
+		done := "Disabled"
+		if args[0] == "enable" {
+			done = "Enabled"
+			err = ctx.Bot().EnableCommand(scope, com.Name())
+		} else {
+			err = ctx.Bot().DisableCommand(scope, com.Name())
+		}
+		if err != nil {
+			ctx.Logger().Errorf("Could not %s command: %v", args[0], err)
+			_, _ = ctx.SendError(fmt.Errorf("could not %s %s", args[0], com.Name()))
+			return
+		}
+
+		_, _ = ctx.SendMessage(fmt.Sprintf("%s `%s` in %s", done, com.Name(), where))
+	},
+		WithCategory("Admin"),
+		WithUsage("<enable|disable> <command> [here]"),
+		WithParameters(
+			Parameter{Name: "enable|disable", Description: "Whether to enable or disable the command"},
+			Parameter{Name: "command", Description: "The command to change"},
+			Parameter{Name: "here", Description: "Only change the command in this channel", Optional: true},
+		),
+		WithExamples(name+" disable ping", name+" enable ping here"),
+		WithChecks(GuildOnly()),
+		WithPermissions(discordgo.PermissionManageServer),
+	)
+}
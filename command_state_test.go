@@ -0,0 +1,243 @@
+package fuzzy_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/fvdveen/fuzzy"
+	"github.com/fvdveen/fuzzy/fuzzytest"
+)
+
+func TestCommandEnabled(t *testing.T) {
+	const (
+		guild   = fuzzytest.DefaultGuildID
+		channel = fuzzytest.DefaultChannelID
+		other   = "3001"
+	)
+
+	tests := []struct {
+		name    string
+		setup   func(*fuzzy.Bot) error
+		command string
+		channel string
+		enabled bool
+	}{
+		{
+			name:    "enabled",
+			setup:   func(*fuzzy.Bot) error { return nil },
+			channel: channel,
+			enabled: true,
+		},
+		{
+			name:    "config",
+			setup:   func(b *fuzzy.Bot) error { return b.EnableCommand(guild, "pong") },
+			command: "pong",
+			channel: channel,
+		},
+		{
+			name: "setting",
+			setup: func(b *fuzzy.Bot) error {
+				return b.SetGuildSetting(guild, fuzzy.DisabledCommandsSetting, []string{"p"})
+			},
+			channel: channel,
+		},
+		{
+			name:    "guild",
+			setup:   func(b *fuzzy.Bot) error { return b.DisableCommand(guild, "ping") },
+			channel: channel,
+		},
+		{
+			name:    "channel",
+			setup:   func(b *fuzzy.Bot) error { return b.DisableCommand(channel, "ping") },
+			channel: channel,
+		},
+		{
+			name:    "other channel",
+			setup:   func(b *fuzzy.Bot) error { return b.DisableCommand(other, "ping") },
+			channel: channel,
+			enabled: true,
+		},
+		{
+			name: "channel does not override guild",
+			setup: func(b *fuzzy.Bot) error {
+				if err := b.DisableCommand(guild, "ping"); err != nil {
+					return err
+				}
+				return b.EnableCommand(channel, "ping")
+			},
+			channel: channel,
+		},
+		{
+			name: "enabled again",
+			setup: func(b *fuzzy.Bot) error {
+				if err := b.DisableCommand(guild, "ping"); err != nil {
+					return err
+				}
+				return b.EnableCommand(guild, "p")
+			},
+			channel: channel,
+			enabled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := fuzzytest.New(fuzzy.WithConfig(&fuzzy.Config{Token: "token", Prefix: "!", LogLevel: fuzzy.LogError, DisabledCommands: []string{"pong"}}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			b := h.Bot
+			if err := b.RegisterCommand(
+				fuzzy.NewCommand("ping", "", func(fuzzy.Context) {}, fuzzy.WithAliases("p")),
+				fuzzy.NewCommand("pong", "", func(fuzzy.Context) {}),
+			); err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.setup(b); err != nil {
+				t.Fatal(err)
+			}
+
+			name := tt.command
+			if name == "" {
+				name = "ping"
+			}
+			enabled, err := b.CommandEnabled(guild, tt.channel, name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if enabled != tt.enabled {
+				t.Fatalf("expected enabled to be %t got %t", tt.enabled, enabled)
+			}
+		})
+	}
+}
+
+func TestCommandStateCommand(t *testing.T) {
+	h, err := fuzzytest.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.SetOwner(fuzzytest.DefaultGuildID, fuzzytest.DefaultUserID); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Bot.RegisterCommand(fuzzy.CommandStateCommand(), fuzzy.NewCommand("ping", "", func(ctx fuzzy.Context) {
+		_, _ = ctx.SendMessage("pong")
+	})); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		in      string
+		reply   string
+		err     bool
+		enabled bool
+	}{
+		{in: "!command disable ping", reply: "Disabled `ping` in this server"},
+		{in: "!ping"},
+		{in: "!command enable ping here", err: true},
+		{in: "!ping"},
+		{in: "!command enable ping", reply: "Enabled `ping` in this server", enabled: true},
+		{in: "!command disable ping here", reply: "Disabled `ping` in this channel"},
+		{in: "!ping"},
+		{in: "!command enable ping here", reply: "Enabled `ping` in this channel", enabled: true},
+		{in: "!ping", reply: "pong", enabled: true},
+		{in: "!command disable command", err: true, enabled: true},
+	}
+
+	for _, tt := range tests {
+		h.Reset()
+		h.Say(tt.in)
+
+		m := h.LastMessage()
+		switch {
+		case tt.err:
+			if m == nil || len(m.Embeds) != 1 {
+				t.Fatalf("%s: expected an error got %v", tt.in, m)
+			}
+		case tt.reply == "":
+			if m != nil {
+				t.Fatalf("%s: expected no reply got %q", tt.in, m.Content)
+			}
+		case m == nil || m.Content != tt.reply:
+			t.Fatalf("%s: expected reply %q got %v", tt.in, tt.reply, m)
+		}
+
+		enabled, err := h.Bot.CommandEnabled(fuzzytest.DefaultGuildID, fuzzytest.DefaultChannelID, "ping")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if enabled != tt.enabled {
+			t.Fatalf("%s: expected enabled to be %t got %t", tt.in, tt.enabled, enabled)
+		}
+	}
+}
+
+func TestUnregisterCommand(t *testing.T) {
+	b, err := fuzzy.New(fuzzy.WithConfig(&fuzzy.Config{Token: "token", Prefix: "!"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.RegisterCommand(
+		fuzzy.NewCommand("ping", "", func(fuzzy.Context) {}, fuzzy.WithAliases("p")),
+		fuzzy.NewCommand("pong", "", func(fuzzy.Context) {}),
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := b.UnregisterCommand("pong", "unknown"); err != fuzzy.ErrUnknownCommand {
+		t.Fatalf("expected error %v got %v", fuzzy.ErrUnknownCommand, err)
+	}
+	if l := len(b.Commands()); l != 2 {
+		t.Fatalf("expected the commands to stay registered got %d commands", l)
+	}
+
+	if err := b.UnregisterCommand("p"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Command("ping"); err != fuzzy.ErrUnknownCommand {
+		t.Fatalf("expected ping to be removed got %v", err)
+	}
+	if _, err := b.Command("pong"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the name can be used again after unregistering
+	if err := b.RegisterCommand(fuzzy.NewCommand("ping", "", func(fuzzy.Context) {})); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCommandsConcurrent(t *testing.T) {
+	h, err := fuzzytest.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := h.Bot
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := fmt.Sprintf("command%d", i)
+			for j := 0; j < 20; j++ {
+				if err := b.RegisterCommand(fuzzy.NewCommand(name, "", func(fuzzy.Context) {})); err != nil {
+					t.Error(err)
+					return
+				}
+				_ = b.Commands()
+				h.Say("!" + name)
+				if err := b.UnregisterCommand(name); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if cs := b.Commands(); len(cs) != 0 {
+		t.Fatalf("expected no commands got %d", len(cs))
+	}
+}
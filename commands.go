@@ -1,7 +1,5 @@
 package fuzzy
 
-import "fmt"
-
 // Command is a action performed by the bot triggered by the string returned by Name
 type Command interface {
 	CommandHandler
@@ -11,6 +9,45 @@ type Command interface {
 	Description() string
 }
 
+// DetailedCommand is a command that gives extra information about itself
+// it is optional, commands that do not implement it have no details
+type DetailedCommand interface {
+	Command
+
+	Details() CommandDetails
+}
+
+// CommandDetails holds the optional information about a command
+type CommandDetails struct {
+	Category string
+	// Usage shows the arguments of the command, eg. "<user> [reason]"
+	Usage      string
+	Examples   []string
+	Aliases    []string
+	Parameters []Parameter
+	// Hidden commands are not shown by the help command
+	Hidden bool
+	// Permissions are the permissions a user needs to run the command
+	Permissions int64
+	// Checks must all pass before the command is run
+	Checks []Check
+}
+
+// Parameter describes an argument of a command
+type Parameter struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Optional    bool   `json:"optional,omitempty"`
+}
+
+// Details returns the details of the command if it has any
+func Details(c Command) CommandDetails {
+	if dc, ok := c.(DetailedCommand); ok {
+		return dc.Details()
+	}
+	return CommandDetails{}
+}
+
 // CommandHandler handles a command by the client
 type CommandHandler interface {
 	Handle(Context)
@@ -24,21 +61,88 @@ func (com CommandHandlerFunc) Handle(c Context) {
 	com(c)
 }
 
+// CommandOption sets one of the details of a command created by NewCommand
+type CommandOption func(*CommandDetails)
+
+// WithCategory sets the category of the command
+func WithCategory(c string) CommandOption {
+	return func(d *CommandDetails) {
+		d.Category = c
+	}
+}
+
+// WithUsage sets the usage of the command
+func WithUsage(u string) CommandOption {
+	return func(d *CommandDetails) {
+		d.Usage = u
+	}
+}
+
+// WithExamples adds examples of the command
+func WithExamples(es ...string) CommandOption {
+	return func(d *CommandDetails) {
+		d.Examples = append(d.Examples, es...)
+	}
+}
+
+// WithAliases adds alternative names the command can be triggered by
+func WithAliases(as ...string) CommandOption {
+	return func(d *CommandDetails) {
+		d.Aliases = append(d.Aliases, as...)
+	}
+}
+
+// WithParameters adds parameters to the command
+func WithParameters(ps ...Parameter) CommandOption {
+	return func(d *CommandDetails) {
+		d.Parameters = append(d.Parameters, ps...)
+	}
+}
+
+// WithChecks adds checks that must pass before the command is run
+func WithChecks(cs ...Check) CommandOption {
+	return func(d *CommandDetails) {
+		d.Checks = append(d.Checks, cs...)
+	}
+}
+
+// WithPermissions only allows users with all the permissions to run the command
+func WithPermissions(perms int64) CommandOption {
+	return func(d *CommandDetails) {
+		d.Permissions |= perms
+		d.Checks = append(d.Checks, RequirePermissions(perms))
+	}
+}
+
+// Hidden hides the command from the help command
+func Hidden() CommandOption {
+	return func(d *CommandDetails) {
+		d.Hidden = true
+	}
+}
+
 // textCommand is a implementation of command
 type textCommand struct {
 	name        string
 	description string
+	details     CommandDetails
 
 	run func(Context)
 }
 
 // NewCommand creates a new Command
-func NewCommand(n, d string, h func(Context)) Command {
-	return &textCommand{
+func NewCommand(n, d string, h func(Context), opts ...CommandOption) Command {
+	c := &textCommand{
 		name:        n,
 		description: d,
 		run:         h,
 	}
+
+	for _, opt := range opts {
+		opt(&c.details)
+	}
+
+	return c
 }
 
 // Name gives the name of the command
@@ -51,19 +155,17 @@ func (c textCommand) Description() string {
 	return c.description
 }
 
+// Details gives the details of the command
+func (c textCommand) Details() CommandDetails {
+	return c.details
+}
+
 // Run runs the command
 func (c textCommand) Handle(ctx Context) {
 	c.run(ctx)
 }
 
-// HelpCommand is a standard help command
-func HelpCommand(t, d string) Command {
-	return NewCommand("help", "Shows all commands", func(ctx Context) {
-		msg := fmt.Sprintf("%s - Commands:", t)
-		for _, com := range ctx.Bot().Commands() {
-			msg = fmt.Sprintf("%s\n`%s%s` %s", msg, ctx.Bot().Config().Prefix, com.Name(), com.Description())
-		}
-
-		ctx.SendMessage(msg)
-	})
+// commandNames returns the name and the aliases of the command
+func commandNames(c Command) []string {
+	return append([]string{c.Name()}, Details(c).Aliases...)
 }
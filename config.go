@@ -1,9 +1,345 @@
 package fuzzy
 
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DefaultEnvPrefix is the prefix of the environment variables read by FromEnv
+const DefaultEnvPrefix = "FUZZY"
+
 // Config holds all options for the bot
 type Config struct {
-	Token      string
-	Prefix     string
-	InviteLink string
-	LogLevel   LogLevel
+	Token string `json:"token"`
+	// TokenFile is a file containing the token, it is used when Token is empty
+	TokenFile  string   `json:"token_file"`
+	Prefix     string   `json:"prefix"`
+	InviteLink string   `json:"invite_link"`
+	LogLevel   LogLevel `json:"log_level"`
+
+	// MaxMessageChunks is the maximum amount of messages a long message is split into
+	// longer messages are sent as a text file, 0 means there is no maximum
+	MaxMessageChunks int `json:"max_message_chunks"`
+
+	// OwnerIDs are the IDs of the users who own the bot
+	OwnerIDs []string `json:"owner_ids"`
+	// DisabledCommands are disabled everywhere
+	DisabledCommands []string `json:"disabled_commands"`
+
+	// Modules are the config sections of modules by module name, see Config.Module
+	Modules map[string]json.RawMessage `json:"modules"`
+}
+
+// DefaultConfig returns the config used by LoadConfig before anything is loaded
+func DefaultConfig() *Config {
+	return &Config{
+		Prefix:   "!",
+		LogLevel: LogInfo,
+	}
+}
+
+// Module decodes the config section of the module into v
+// v is left unchanged when there is no section for the module
+func (c *Config) Module(name string, v interface{}) error {
+	raw, ok := c.Modules[name]
+	if !ok {
+		return nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("could not decode config of module %s: %v", name, err)
+	}
+	return nil
+}
+
+// IsOwner reports if the user is one of the owners of the bot
+func (c *Config) IsOwner(userID string) bool {
+	for _, id := range c.OwnerIDs {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks if all required options are set
+func (c *Config) Validate() error {
+	if c.Token == "" {
+		return fmt.Errorf("%v: no token", ErrInvalidConfig)
+	}
+	if c.Prefix == "" {
+		return fmt.Errorf("%v: no prefix", ErrInvalidConfig)
+	}
+	if c.MaxMessageChunks < 0 {
+		return fmt.Errorf("%v: max message chunks can not be negative", ErrInvalidConfig)
+	}
+	return nil
+}
+
+// configField is an option that can be set from the environment and flags
+type configField struct {
+	name  string
+	usage string
+	set   func(c *Config, v string) error
+}
+
+var configFields = []configField{
+	{"token", "The discord bot token", func(c *Config, v string) error {
+		c.Token = v
+		return nil
+	}},
+	{"token-file", "A file containing the discord bot token", func(c *Config, v string) error {
+		c.TokenFile = v
+		return nil
+	}},
+	{"prefix", "The prefix of the commands", func(c *Config, v string) error {
+		c.Prefix = v
+		return nil
+	}},
+	{"invite-link", "The invite link of the bot", func(c *Config, v string) error {
+		c.InviteLink = v
+		return nil
+	}},
+	{"log-level", "The log level (debug, info, warn, error)", func(c *Config, v string) error {
+		return c.LogLevel.UnmarshalText([]byte(v))
+	}},
+	{"max-message-chunks", "The maximum amount of messages a long message is split into", func(c *Config, v string) error {
+		i, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("%s is not a number", v)
+		}
+		c.MaxMessageChunks = i
+		return nil
+	}},
+	{"owner-ids", "Comma separated IDs of the owners of the bot", func(c *Config, v string) error {
+		c.OwnerIDs = splitList(v)
+		return nil
+	}},
+	{"disabled-commands", "Comma separated commands that are disabled everywhere", func(c *Config, v string) error {
+		c.DisabledCommands = splitList(v)
+		return nil
+	}},
+}
+
+func splitList(s string) []string {
+	var l []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			l = append(l, v)
+		}
+	}
+	return l
+}
+
+// ConfigOption sets a source of LoadConfig
+type ConfigOption func(*configLoader)
+
+// ConfigDecoder decodes a config file into generic values like map[string]interface{}
+// the values are re-encoded as JSON so only the JSON tags of Config are needed
+type ConfigDecoder func([]byte) (interface{}, error)
+
+// configLoader holds the sources of a config
+type configLoader struct {
+	defaults  *Config
+	file      string
+	envPrefix string
+	env       bool
+	args      []string
+	flags     bool
+	decoders  map[string]ConfigDecoder
+}
+
+// WithDefaults sets the config that is used before anything is loaded, DefaultConfig is used otherwise
+func WithDefaults(c Config) ConfigOption {
+	return func(l *configLoader) {
+		l.defaults = &c
+	}
+}
+
+// FromFile loads the config from a file, the format is chosen by the extension
+// JSON files are always supported, other formats need a decoder from WithConfigDecoder
+// the file is not required to exist, unlike a file set by FUZZY_CONFIG or -config
+func FromFile(path string) ConfigOption {
+	return func(l *configLoader) {
+		l.file = path
+	}
+}
+
+// WithConfigDecoder decodes config files with the extensions, like ".yaml", with d
+// decoders for YAML and TOML are in the decoders/yaml and decoders/toml packages
+func WithConfigDecoder(d ConfigDecoder, exts ...string) ConfigOption {
+	return func(l *configLoader) {
+		if l.decoders == nil {
+			l.decoders = make(map[string]ConfigDecoder)
+		}
+		for _, ext := range exts {
+			l.decoders[strings.ToLower(ext)] = d
+		}
+	}
+}
+
+// FromEnv loads the config from environment variables like FUZZY_TOKEN and FUZZY_LOG_LEVEL
+// FUZZY_CONFIG sets the config file, an empty prefix uses DefaultEnvPrefix
+func FromEnv(prefix string) ConfigOption {
+	return func(l *configLoader) {
+		if prefix == "" {
+			prefix = DefaultEnvPrefix
+		}
+		l.envPrefix = prefix
+		l.env = true
+	}
+}
+
+// FromFlags loads the config from command line flags like -token and -log-level
+// -config sets the config file, only flags in args that are set override the config
+func FromFlags(args []string) ConfigOption {
+	return func(l *configLoader) {
+		l.args = args
+		l.flags = true
+	}
+}
+
+// LoadConfig loads the config from its sources and validates it
+// later sources override earlier ones: defaults, file, environment and flags
+func LoadConfig(opts ...ConfigOption) (*Config, error) {
+	l := &configLoader{}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l.load()
+}
+
+func (l *configLoader) load() (*Config, error) {
+	c := DefaultConfig()
+	if l.defaults != nil {
+		d := *l.defaults
+		c = &d
+	}
+
+	env, flags, err := l.overrides()
+	if err != nil {
+		return nil, err
+	}
+	if file, named := l.path(env, flags); file != "" {
+		if err := l.decodeFile(file, named, c); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, src := range []map[string]string{env, flags} {
+		for _, f := range configFields {
+			v, ok := src[f.name]
+			if !ok {
+				continue
+			}
+			if err := f.set(c, v); err != nil {
+				return nil, fmt.Errorf("%v: %s: %v", ErrInvalidConfig, f.name, err)
+			}
+		}
+	}
+
+	if c.Token == "" && c.TokenFile != "" {
+		b, err := ioutil.ReadFile(c.TokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read token file: %v", err)
+		}
+		c.Token = strings.TrimSpace(string(b))
+	}
+
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// overrides returns the options set in the environment and the flags
+func (l *configLoader) overrides() (env, flags map[string]string, err error) {
+	env = map[string]string{}
+	if l.env {
+		for _, f := range append(configFields, configField{name: "config"}) {
+			k := l.envPrefix + "_" + strings.ToUpper(strings.Replace(f.name, "-", "_", -1))
+			if v, ok := os.LookupEnv(k); ok {
+				env[f.name] = v
+			}
+		}
+	}
+
+	flags = map[string]string{}
+	if l.flags {
+		fs := flag.NewFlagSet("fuzzy", flag.ContinueOnError)
+		vs := map[string]*string{"config": fs.String("config", "", "The config file")}
+		for _, f := range configFields {
+			vs[f.name] = fs.String(f.name, "", f.usage)
+		}
+		if err := fs.Parse(l.args); err != nil {
+			return nil, nil, fmt.Errorf("%v: %v", ErrInvalidConfig, err)
+		}
+		fs.Visit(func(f *flag.Flag) {
+			flags[f.Name] = *vs[f.Name]
+		})
+	}
+
+	return env, flags, nil
+}
+
+// path returns the config file, the environment and flags override the file of FromFile
+// named reports if the file was set by the environment or flags
+func (l *configLoader) path(env, flags map[string]string) (file string, named bool) {
+	file = l.file
+	for _, src := range []map[string]string{env, flags} {
+		if v, ok := src["config"]; ok {
+			file, named = v, true
+		}
+	}
+	return file, named
+}
+
+// decodeFile decodes the file into c
+// a file that does not exist is ignored unless it is required
+func (l *configLoader) decodeFile(path string, required bool, c *Config) error {
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) && !required {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("could not read config file: %v", err)
+	}
+
+	// all formats are decoded into generic values and re-encoded as JSON
+	// so only the JSON tags of Config are needed and module sections keep their raw form
+	ext := strings.ToLower(filepath.Ext(path))
+	d, ok := l.decoders[ext]
+	if !ok && ext == ".json" {
+		d, ok = decodeJSON, true
+	}
+	if !ok {
+		return fmt.Errorf("%v: %s", ErrUnknownConfigFormat, path)
+	}
+	v, err := d(b)
+	if err != nil {
+		return fmt.Errorf("could not decode config file: %v", err)
+	}
+	if v == nil {
+		return nil
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("could not decode config file: %v", err)
+	}
+	if err := json.Unmarshal(raw, c); err != nil {
+		return fmt.Errorf("%v: %v", ErrInvalidConfig, err)
+	}
+	return nil
+}
+
+// decodeJSON is the ConfigDecoder of JSON files
+func decodeJSON(b []byte) (interface{}, error) {
+	var v interface{}
+	err := json.Unmarshal(b, &v)
+	return v, err
 }
@@ -0,0 +1,125 @@
+package fuzzy_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fvdveen/fuzzy"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "fuzzy.json")
+	if err := ioutil.WriteFile(file, []byte(`{
+		"token": "file-token",
+		"prefix": "?",
+		"log_level": "warn",
+		"owner_ids": ["1"],
+		"modules": {"music": {"volume": 80}}
+	}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tokenFile := filepath.Join(dir, "token")
+	if err := ioutil.WriteFile(tokenFile, []byte("secret-token\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv("FUZZY_TEST_PREFIX", "env-")
+	os.Setenv("FUZZY_TEST_LOG_LEVEL", "debug")
+	defer os.Unsetenv("FUZZY_TEST_PREFIX")
+	defer os.Unsetenv("FUZZY_TEST_LOG_LEVEL")
+
+	c, err := fuzzy.LoadConfig(
+		fuzzy.FromFile(file),
+		fuzzy.FromEnv("FUZZY_TEST"),
+		fuzzy.FromFlags([]string{"-log-level", "error", "-owner-ids", "2, 3"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Token != "file-token" {
+		t.Errorf("expected token from the file got %s", c.Token)
+	}
+	if c.Prefix != "env-" {
+		t.Errorf("expected prefix from the environment got %s", c.Prefix)
+	}
+	if c.LogLevel != fuzzy.LogError {
+		t.Errorf("expected log level from the flags got %v", c.LogLevel)
+	}
+	if exp := []string{"2", "3"}; !reflect.DeepEqual(c.OwnerIDs, exp) {
+		t.Errorf("expected owners %v got %v", exp, c.OwnerIDs)
+	}
+
+	var music struct {
+		Volume int
+		Loop   bool
+	}
+	music.Loop = true
+	if err := c.Module("music", &music); err != nil {
+		t.Fatal(err)
+	}
+	if music.Volume != 80 || !music.Loop {
+		t.Errorf("expected the module section to be decoded over the defaults got %+v", music)
+	}
+
+	c, err = fuzzy.LoadConfig(fuzzy.FromFlags([]string{"-token-file", tokenFile}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Token != "secret-token" {
+		t.Errorf("expected token from the token file got %s", c.Token)
+	}
+
+	if _, err := fuzzy.LoadConfig(fuzzy.WithDefaults(fuzzy.Config{Prefix: "!"})); err == nil {
+		t.Error("expected an error without a token")
+	}
+
+	// only the file of FromFile may be missing
+	missing := filepath.Join(dir, "missing.json")
+	if _, err := fuzzy.LoadConfig(fuzzy.FromFile(missing), fuzzy.FromFlags([]string{"-token", "token"})); err != nil {
+		t.Errorf("expected a missing default file to be ignored got %v", err)
+	}
+	if _, err := fuzzy.LoadConfig(fuzzy.FromFlags([]string{"-token", "token", "-config", missing})); err == nil {
+		t.Error("expected an error for a missing config file set by the flags")
+	}
+	os.Setenv("FUZZY_TEST_CONFIG", missing)
+	defer os.Unsetenv("FUZZY_TEST_CONFIG")
+	if _, err := fuzzy.LoadConfig(fuzzy.FromFile(file), fuzzy.FromEnv("FUZZY_TEST")); err == nil {
+		t.Error("expected an error for a missing config file set by the environment")
+	}
+}
+
+func TestConfigDecoder(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "fuzzy.conf")
+	if err := ioutil.WriteFile(file, []byte("token=conf-token\nprefix=?"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// decode lines of key=value
+	decode := func(b []byte) (interface{}, error) {
+		m := map[string]interface{}{}
+		for _, l := range strings.Split(string(b), "\n") {
+			kv := strings.SplitN(l, "=", 2)
+			m[kv[0]] = kv[1]
+		}
+		return m, nil
+	}
+
+	if _, err := fuzzy.LoadConfig(fuzzy.FromFile(file)); err == nil || !strings.HasPrefix(err.Error(), fuzzy.ErrUnknownConfigFormat.Error()) {
+		t.Fatalf("expected error %v got %v", fuzzy.ErrUnknownConfigFormat, err)
+	}
+
+	c, err := fuzzy.LoadConfig(fuzzy.FromFile(file), fuzzy.WithConfigDecoder(decode, ".CONF"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Token != "conf-token" || c.Prefix != "?" {
+		t.Fatalf("expected the config from the decoder got token %q and prefix %q", c.Token, c.Prefix)
+	}
+}
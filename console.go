@@ -0,0 +1,424 @@
+package fuzzy
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ConsoleEnv is the environment variable used by fuzzy-console
+// when it is set Bot.RunTool runs the console on stdin and stdout, Bot.Open ignores it
+// its value is a comma separated list of user, guild, channel and voice options, e.g. "user=42,guild=1"
+const ConsoleEnv = "FUZZY_CONSOLE"
+
+// consoleReact is the console command that adds a reaction to a message
+const consoleReact = ":react"
+
+// ConsoleOption sets an option of the console
+type ConsoleOption func(*consoleTransport)
+
+// ConsoleUser sets the user the console sends messages as
+func ConsoleUser(id, name string) ConsoleOption {
+	return func(t *consoleTransport) {
+		t.user = &discordgo.User{ID: id, Username: name}
+	}
+}
+
+// ConsoleGuild sets the guild the console sends messages in, an empty ID sends direct messages
+func ConsoleGuild(id, name string) ConsoleOption {
+	return func(t *consoleTransport) {
+		t.guild.ID = id
+		t.guild.Name = name
+	}
+}
+
+// ConsoleChannel sets the channel the console sends messages in
+func ConsoleChannel(id, name string) ConsoleOption {
+	return func(t *consoleTransport) {
+		t.channel.ID = id
+		t.channel.Name = name
+	}
+}
+
+// ConsoleVoiceChannel puts the user of the console in the voice channel
+func ConsoleVoiceChannel(id string) ConsoleOption {
+	return func(t *consoleTransport) {
+		t.voiceChannel = id
+	}
+}
+
+// runConsole runs the console with the options in ConsoleEnv
+// it returns false if the environment variable is not set
+func (b *Bot) runConsole() (bool, error) {
+	v, ok := os.LookupEnv(ConsoleEnv)
+	if !ok {
+		return false, nil
+	}
+
+	opts, err := parseConsoleOptions(v)
+	if err != nil {
+		return true, err
+	}
+	return true, b.Console(os.Stdin, os.Stdout, opts...)
+}
+
+// parseConsoleOptions parses the value of ConsoleEnv
+func parseConsoleOptions(v string) ([]ConsoleOption, error) {
+	var opts []ConsoleOption
+	for _, kv := range strings.Split(v, ",") {
+		kv = strings.TrimSpace(kv)
+		if kv == "" || kv == "1" {
+			continue
+		}
+		i := strings.Index(kv, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("%v: %s", ErrInvalidConsoleOption, kv)
+		}
+		k, val := kv[:i], kv[i+1:]
+		switch k {
+		case "user":
+			opts = append(opts, ConsoleUser(val, "console"))
+		case "guild":
+			opts = append(opts, ConsoleGuild(val, "console"))
+		case "channel":
+			opts = append(opts, ConsoleChannel(val, "console"))
+		case "voice":
+			opts = append(opts, ConsoleVoiceChannel(val))
+		default:
+			return nil, fmt.Errorf("%v: %s", ErrInvalidConsoleOption, kv)
+		}
+	}
+	return opts, nil
+}
+
+// Console runs the bot without discord, every line read from r is a message from the console user
+// and everything the bot does is written to w, lines like ":react <message id> <emoji>" add reactions
+// it returns when r is done and all commands have returned
+// the bot uses the console instead of its transport until then, so it must not be open while the console runs
+func (b *Bot) Console(r io.Reader, w io.Writer, opts ...ConsoleOption) error {
+	t := &consoleTransport{
+		w:       w,
+		bot:     &discordgo.User{ID: "1", Username: "fuzzy", Bot: true},
+		user:    &discordgo.User{ID: "2", Username: "console"},
+		guild:   &discordgo.Guild{ID: "3", Name: "console"},
+		channel: &discordgo.Channel{ID: "4", Name: "console"},
+		nextID:  100,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	t.channel.GuildID = t.guild.ID
+	if t.guild.ID != "" {
+		t.guild.Channels = []*discordgo.Channel{t.channel}
+		t.guild.Members = []*discordgo.Member{{GuildID: t.guild.ID, User: t.user}}
+	} else {
+		t.channel.Type = discordgo.ChannelTypeDM
+	}
+	prev := b.setTransport(t)
+	defer b.setTransport(prev)
+
+	b.scheduler.start()
+	defer b.scheduler.stop()
+
+	var handled []<-chan struct{}
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+
+		var e interface{}
+		if f := strings.Fields(line); f[0] == consoleReact {
+			if len(f) != 3 {
+				t.printf("usage: %s <message id> <emoji>", consoleReact)
+				continue
+			}
+			e = &discordgo.MessageReactionAdd{MessageReaction: &discordgo.MessageReaction{
+				UserID:    t.user.ID,
+				MessageID: f[1],
+				ChannelID: t.channel.ID,
+				GuildID:   t.guild.ID,
+				Emoji:     discordgo.Emoji{Name: f[2]},
+			}}
+		} else {
+			e = &discordgo.MessageCreate{Message: &discordgo.Message{
+				ID:        t.newID(),
+				ChannelID: t.channel.ID,
+				GuildID:   t.guild.ID,
+				Content:   line,
+				Timestamp: time.Now(),
+				Author:    t.user,
+			}}
+		}
+
+		// the next line is read once the commands are done or wait for it, so the lines are handled in order
+		_, waits, _ := b.activity.state()
+		done := b.publishAsync(e)
+		handled = append(handled, done)
+		_ = b.waitIdle(context.Background(), waits, done)
+	}
+
+	for _, done := range handled {
+		<-done
+	}
+	return sc.Err()
+}
+
+// consoleTransport is a Transport which writes everything the bot does to a writer
+type consoleTransport struct {
+	mu sync.Mutex
+	w  io.Writer
+
+	bot          *discordgo.User
+	user         *discordgo.User
+	guild        *discordgo.Guild
+	channel      *discordgo.Channel
+	voiceChannel string
+	nextID       int64
+}
+
+func (t *consoleTransport) newID() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.nextID++
+	return strconv.FormatInt(t.nextID, 10)
+}
+
+func (t *consoleTransport) printf(format string, a ...interface{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	fmt.Fprintf(t.w, format+"\n", a...)
+}
+
+// where describes the channel
+func (t *consoleTransport) where(channelID string) string {
+	if channelID == t.channel.ID {
+		return ""
+	}
+	if channelID == "dm" {
+		return " (dm)"
+	}
+	return " (#" + channelID + ")"
+}
+
+// sentEmbeds combines the single embed and the embeds of a sent or edited message
+func sentEmbeds(e *discordgo.MessageEmbed, es []*discordgo.MessageEmbed) []*discordgo.MessageEmbed {
+	if e != nil {
+		return append([]*discordgo.MessageEmbed{e}, es...)
+	}
+	return es
+}
+
+// editedEmbeds returns the embeds of the edit
+// the type of MessageEdit.Embeds differs between discordgo versions so they are read from its JSON form
+func editedEmbeds(e *discordgo.MessageEdit) ([]*discordgo.MessageEmbed, error) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode edit: %v", err)
+	}
+	var data struct {
+		Embeds []*discordgo.MessageEmbed `json:"embeds"`
+	}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return nil, fmt.Errorf("could not decode edit: %v", err)
+	}
+	return data.Embeds, nil
+}
+
+func (t *consoleTransport) ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend) (*discordgo.Message, error) {
+	m := &discordgo.Message{
+		ID:        t.newID(),
+		ChannelID: channelID,
+		GuildID:   t.guild.ID,
+		Content:   data.Content,
+		Author:    t.bot,
+	}
+	m.Embeds = sentEmbeds(data.Embed, data.Embeds)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "[%s] %s%s:", m.ID, t.bot.Username, t.where(channelID))
+	if data.Content != "" {
+		fmt.Fprintf(&sb, " %s", data.Content)
+	}
+	for _, e := range m.Embeds {
+		writeConsoleEmbed(&sb, e)
+	}
+	for _, f := range data.Files {
+		n, _ := io.Copy(ioutil.Discard, f.Reader)
+		fmt.Fprintf(&sb, "\n  [file %s, %d bytes]", f.Name, n)
+	}
+	t.printf("%s", sb.String())
+
+	return m, nil
+}
+
+func (t *consoleTransport) ChannelMessageEditComplex(e *discordgo.MessageEdit) (*discordgo.Message, error) {
+	m := &discordgo.Message{
+		ID:        e.ID,
+		ChannelID: e.Channel,
+		GuildID:   t.guild.ID,
+		Author:    t.bot,
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "[%s] %s%s (edited):", e.ID, t.bot.Username, t.where(e.Channel))
+	if e.Content != nil {
+		m.Content = *e.Content
+		fmt.Fprintf(&sb, " %s", *e.Content)
+	}
+	es, err := editedEmbeds(e)
+	if err != nil {
+		return nil, err
+	}
+	m.Embeds = sentEmbeds(e.Embed, es)
+	for _, em := range m.Embeds {
+		writeConsoleEmbed(&sb, em)
+	}
+	t.printf("%s", sb.String())
+
+	return m, nil
+}
+
+func (t *consoleTransport) ChannelMessageDelete(channelID, messageID string) error {
+	t.printf("[%s] deleted", messageID)
+	return nil
+}
+
+func (t *consoleTransport) ChannelTyping(channelID string) error {
+	return nil
+}
+
+func (t *consoleTransport) MessageReactionAdd(channelID, messageID, emoji string) error {
+	t.printf("[%s] %s reacted %s", messageID, t.bot.Username, emoji)
+	return nil
+}
+
+func (t *consoleTransport) MessageReactionRemove(channelID, messageID, emoji, userID string) error {
+	return nil
+}
+
+func (t *consoleTransport) MessageReactionsRemoveAll(channelID, messageID string) error {
+	t.printf("[%s] reactions removed", messageID)
+	return nil
+}
+
+func (t *consoleTransport) UserChannelCreate(userID string) (*discordgo.Channel, error) {
+	return &discordgo.Channel{ID: "dm", Type: discordgo.ChannelTypeDM}, nil
+}
+
+func (t *consoleTransport) Channel(channelID string) (*discordgo.Channel, error) {
+	if channelID == "dm" {
+		return &discordgo.Channel{ID: "dm", Type: discordgo.ChannelTypeDM}, nil
+	}
+	return t.channel, nil
+}
+
+func (t *consoleTransport) Guild(guildID string) (*discordgo.Guild, error) {
+	return t.guild, nil
+}
+
+func (t *consoleTransport) GuildMember(guildID, userID string) (*discordgo.Member, error) {
+	return &discordgo.Member{GuildID: guildID, User: t.user}, nil
+}
+
+// UserChannelPermissions gives everyone all permissions
+func (t *consoleTransport) UserChannelPermissions(userID, channelID string) (int64, error) {
+	return discordgo.PermissionAll, nil
+}
+
+func (t *consoleTransport) VoiceState(guildID, userID string) (*discordgo.VoiceState, error) {
+	if t.voiceChannel == "" || userID != t.user.ID {
+		return nil, ErrUnknownVoiceState
+	}
+	return &discordgo.VoiceState{GuildID: guildID, UserID: userID, ChannelID: t.voiceChannel}, nil
+}
+
+func (t *consoleTransport) BotUser() *discordgo.User {
+	return t.bot
+}
+
+func (t *consoleTransport) ChannelVoiceJoin(guildID, channelID string, mute, deaf bool) (VoiceConnection, error) {
+	t.printf("%s joined voice channel %s", t.bot.Username, channelID)
+
+	vc := &consoleVoiceConnection{
+		t:       t,
+		send:    make(chan []byte),
+		done:    make(chan struct{}),
+		drained: make(chan struct{}),
+	}
+	go vc.drain()
+	return vc, nil
+}
+
+// consoleVoiceConnection counts the frames sent to it
+type consoleVoiceConnection struct {
+	t       *consoleTransport
+	send    chan []byte
+	done    chan struct{}
+	drained chan struct{}
+	frames  int
+}
+
+func (vc *consoleVoiceConnection) drain() {
+	defer close(vc.drained)
+	for {
+		select {
+		case <-vc.send:
+			vc.frames++
+		case <-vc.done:
+			return
+		}
+	}
+}
+
+func (vc *consoleVoiceConnection) Speaking(bool) error {
+	return nil
+}
+
+func (vc *consoleVoiceConnection) OpusSend() chan<- []byte {
+	return vc.send
+}
+
+func (vc *consoleVoiceConnection) Disconnect() error {
+	close(vc.done)
+	<-vc.drained
+	vc.t.printf("%s left voice after %d frames", vc.t.bot.Username, vc.frames)
+	return nil
+}
+
+// writeConsoleEmbed writes the embed as indented text
+func writeConsoleEmbed(w io.Writer, e *discordgo.MessageEmbed) {
+	if e.Author != nil && e.Author.Name != "" {
+		fmt.Fprintf(w, "\n  | %s", e.Author.Name)
+	}
+	if e.Title != "" {
+		fmt.Fprintf(w, "\n  | **%s**", e.Title)
+	}
+	for _, l := range strings.Split(e.Description, "\n") {
+		if l != "" {
+			fmt.Fprintf(w, "\n  | %s", l)
+		}
+	}
+	for _, f := range e.Fields {
+		fmt.Fprintf(w, "\n  | %s: %s", f.Name, strings.Replace(f.Value, "\n", "\n  |   ", -1))
+	}
+	if e.Image != nil {
+		fmt.Fprintf(w, "\n  | [image %s]", e.Image.URL)
+	}
+	if e.Footer != nil && e.Footer.Text != "" {
+		fmt.Fprintf(w, "\n  | -- %s", e.Footer.Text)
+	}
+}
@@ -0,0 +1,189 @@
+package fuzzy_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/fvdveen/fuzzy"
+)
+
+func TestConsole(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		opts   []fuzzy.ConsoleOption
+		output []string
+	}{
+		{
+			name:   "message",
+			input:  "!ping\n",
+			output: []string{"fuzzy: pong"},
+		},
+		{
+			name:   "embed",
+			input:  "!card\n",
+			output: []string{"fuzzy:", "  | **Card**", "  | some text", "  | field: value"},
+		},
+		{
+			name:   "embeds",
+			input:  "!cards\n",
+			output: []string{"fuzzy:", "  | **One**", "  | **Two**", "fuzzy (edited):", "  | **Three**"},
+		},
+		{
+			name:   "author",
+			input:  "!whoami\n",
+			opts:   []fuzzy.ConsoleOption{fuzzy.ConsoleUser("42", "dev")},
+			output: []string{"fuzzy: dev"},
+		},
+		{
+			name:   "direct message",
+			input:  "!where\n",
+			opts:   []fuzzy.ConsoleOption{fuzzy.ConsoleGuild("", "")},
+			output: []string{"fuzzy: dm"},
+		},
+		{
+			name:   "order",
+			input:  "!ping\n!whoami\n!ping\n",
+			output: []string{"fuzzy: pong", "fuzzy: console", "fuzzy: pong"},
+		},
+		{
+			name:   "dialog",
+			input:  "!signup\nalice\nold\n30\n!ping\n",
+			output: []string{"fuzzy: name?", "fuzzy: age?", "fuzzy: Invalid answer: \"old\" is not a number", "age?", "fuzzy: alice is 30", "fuzzy: pong"},
+		},
+		{
+			name:   "not a command",
+			input:  "hello\n\n",
+			output: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := fuzzy.New(fuzzy.WithConfig(&fuzzy.Config{Token: "token", Prefix: "!", LogLevel: fuzzy.LogError}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_ = b.RegisterCommand(
+				fuzzy.NewCommand("ping", "", func(ctx fuzzy.Context) {
+					ctx.SendMessage("pong")
+				}),
+				fuzzy.NewCommand("card", "", func(ctx fuzzy.Context) {
+					ctx.SendEmbed(fuzzy.NewEmbed().SetTitle("Card").SetDescription("some text").AddField("field", "value", false).Build())
+				}),
+				fuzzy.NewCommand("cards", "", func(ctx fuzzy.Context) {
+					m, err := ctx.SendComplex(&discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{{Title: "One"}, {Title: "Two"}}})
+					if err != nil {
+						return
+					}
+					e := discordgo.NewMessageEdit(m.ChannelID, m.ID).SetEmbeds([]*discordgo.MessageEmbed{{Title: "Three"}})
+					ctx.Transport().ChannelMessageEditComplex(e)
+				}),
+				fuzzy.NewCommand("whoami", "", func(ctx fuzzy.Context) {
+					ctx.SendMessage(ctx.MessageEvent().Author.Username)
+				}),
+				fuzzy.NewCommand("signup", "", func(ctx fuzzy.Context) {
+					var v struct {
+						Name string
+						Age  int
+					}
+					if err := fuzzy.NewDialog(&fuzzy.Step{Name: "name", Prompt: "name?"}, &fuzzy.Step{Name: "age", Prompt: "age?"}).Run(ctx, &v); err != nil {
+						ctx.SendError(err)
+						return
+					}
+					ctx.SendMessage(fmt.Sprintf("%s is %d", v.Name, v.Age))
+				}),
+				fuzzy.NewCommand("where", "", func(ctx fuzzy.Context) {
+					if ctx.MessageEvent().GuildID == "" {
+						ctx.SendMessage("dm")
+					} else {
+						ctx.SendMessage("guild")
+					}
+				}),
+			)
+
+			tr := b.Transport()
+			var out bytes.Buffer
+			if err := b.Console(strings.NewReader(tt.input), &out, tt.opts...); err != nil {
+				t.Fatal(err)
+			}
+			if b.Transport() != tr {
+				t.Fatal("expected the transport of the bot to be restored")
+			}
+
+			var lines []string
+			for _, l := range strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n") {
+				if l == "" {
+					continue
+				}
+				// strip the message ID
+				if i := strings.Index(l, "] "); strings.HasPrefix(l, "[") && i > 0 {
+					l = l[i+2:]
+				}
+				lines = append(lines, l)
+			}
+			if strings.Join(lines, "\n") != strings.Join(tt.output, "\n") {
+				t.Fatalf("expected output\n%s\ngot\n%s", strings.Join(tt.output, "\n"), out.String())
+			}
+		})
+	}
+}
+
+func TestRunToolConsole(t *testing.T) {
+	in, err := ioutil.TempFile("", "fuzzy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(in.Name())
+	defer in.Close()
+	out, err := ioutil.TempFile("", "fuzzy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(out.Name())
+	defer out.Close()
+	if _, err := in.WriteString("!ping\n"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := in.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+
+	stdin, stdout := os.Stdin, os.Stdout
+	os.Stdin, os.Stdout = in, out
+	defer func() {
+		os.Stdin, os.Stdout = stdin, stdout
+	}()
+	os.Setenv(fuzzy.ConsoleEnv, "user=42")
+	defer os.Unsetenv(fuzzy.ConsoleEnv)
+
+	b, err := fuzzy.New(fuzzy.WithConfig(&fuzzy.Config{Token: "token", Prefix: "!", LogLevel: fuzzy.LogError}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = b.RegisterCommand(fuzzy.NewCommand("ping", "", func(ctx fuzzy.Context) {
+		ctx.SendMessage("pong")
+	}))
+
+	if ok, err := b.RunTool(); !ok || err != nil {
+		t.Fatalf("expected the console to run got %t and %v", ok, err)
+	}
+	data, err := ioutil.ReadFile(out.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(data), "fuzzy: pong\n") {
+		t.Fatalf("expected pong got %q", data)
+	}
+
+	os.Unsetenv(fuzzy.ConsoleEnv)
+	if ok, err := b.RunTool(); ok || err != nil {
+		t.Fatalf("expected no tool to run got %t and %v", ok, err)
+	}
+}
@@ -3,11 +3,16 @@ package fuzzy
 import (
 	"context"
 	"fmt"
+	"io"
+	"strings"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
 )
 
+// typingInterval is how often the typing indicator is renewed, discord shows it for 10 seconds
+const typingInterval = 8 * time.Second
+
 // ContextGenerator creates a Context
 type ContextGenerator func(context.Context, string, *discordgo.MessageCreate, *Bot, *discordgo.Session, Command) Context
 
@@ -18,16 +23,43 @@ type Context interface {
 	MessageEvent() *discordgo.MessageCreate
 	Bot() *Bot
 	Session() *discordgo.Session
+	// Transport is the transport of the bot
+	Transport() Transport
 	Command() Command
 	Logger() Logger
 	Guild() (*discordgo.Guild, error)
+	// GuildStore is the part of the bot's store for the guild of the message, it fails with ErrNoGuild in direct messages
+	GuildStore() Store
+	// UserStore is the part of the bot's store for the author of the message
+	UserStore() Store
+	// GuildSetting returns the value of the setting in the guild of the message
+	GuildSetting(name string) (interface{}, error)
 
 	WithContext(ctx context.Context) Context
 	VoiceHandler() (VoiceHandler, error)
 	PlaySound(VoiceItem) error
 
-	SendMessage(string)
-	SendEmbed(*discordgo.MessageEmbed)
+	// WithAllowedMentions returns a copy of the context which sends messages with the given allowed mentions
+	WithAllowedMentions(*discordgo.MessageAllowedMentions) Context
+
+	SendMessage(string) (*discordgo.Message, error)
+	SendEmbed(*discordgo.MessageEmbed) (*discordgo.Message, error)
+	SendComplex(*discordgo.MessageSend) (*discordgo.Message, error)
+	SendFile(name string, r io.Reader) (*discordgo.Message, error)
+	// SendError sends the error as an embed in the bot's error color
+	SendError(error) (*discordgo.Message, error)
+	// Reply sends a message referencing the message that invoked the command
+	Reply(string) (*discordgo.Message, error)
+	// DM sends a direct message to the author of the command
+	DM(string) (*discordgo.Message, error)
+	// React adds a reaction to the message that invoked the command
+	React(emoji string) error
+	Edit(m *discordgo.Message, content string) (*discordgo.Message, error)
+	// DeleteAfter deletes the message after the duration has passed
+	DeleteAfter(m *discordgo.Message, d time.Duration)
+	// Typing shows the typing indicator until the command handler returns
+	Typing() error
+	WaitForReply(time.Duration) (*discordgo.MessageCreate, error)
 }
 
 type defaultContext struct {
@@ -38,6 +70,8 @@ type defaultContext struct {
 	bot           *Bot
 	sess          *discordgo.Session
 	command       Command
+
+	allowedMentions *discordgo.MessageAllowedMentions
 }
 
 // DefaultContext is the default context generator
@@ -84,6 +118,10 @@ func (ctx *defaultContext) Session() *discordgo.Session {
 	return ctx.sess
 }
 
+func (ctx *defaultContext) Transport() Transport {
+	return ctx.bot.Transport()
+}
+
 func (ctx *defaultContext) Command() Command {
 	return ctx.command
 }
@@ -93,18 +131,30 @@ func (ctx *defaultContext) Logger() Logger {
 }
 
 func (ctx *defaultContext) Guild() (*discordgo.Guild, error) {
-	c, err := ctx.sess.Channel(ctx.messageCreate.ChannelID)
+	c, err := ctx.bot.Transport().Channel(ctx.messageCreate.ChannelID)
 	if err != nil {
 		return nil, fmt.Errorf("could not get channel: %v", err)
 	}
 
-	g, err := ctx.sess.Guild(c.GuildID)
+	g, err := ctx.bot.Transport().Guild(c.GuildID)
 	if err != nil {
 		return nil, fmt.Errorf("could not get guild: %v", err)
 	}
 	return g, nil
 }
 
+func (ctx *defaultContext) GuildStore() Store {
+	return ctx.bot.GuildStore(ctx.messageCreate.GuildID)
+}
+
+func (ctx *defaultContext) UserStore() Store {
+	return ctx.bot.UserStore(ctx.messageCreate.Author.ID)
+}
+
+func (ctx *defaultContext) GuildSetting(name string) (interface{}, error) {
+	return ctx.bot.GuildSetting(ctx.messageCreate.GuildID, name)
+}
+
 func (ctx *defaultContext) WithContext(c context.Context) Context {
 	ctx2 := new(defaultContext)
 	*ctx2 = *ctx
@@ -112,16 +162,130 @@ func (ctx *defaultContext) WithContext(c context.Context) Context {
 	return ctx2
 }
 
-func (ctx *defaultContext) SendMessage(msg string) {
-	_, _ = ctx.sess.ChannelMessageSend(ctx.messageCreate.ChannelID, msg)
+func (ctx *defaultContext) WithAllowedMentions(am *discordgo.MessageAllowedMentions) Context {
+	ctx2 := new(defaultContext)
+	*ctx2 = *ctx
+	ctx2.allowedMentions = am
+	return ctx2
+}
+
+func (ctx *defaultContext) SendMessage(msg string) (*discordgo.Message, error) {
+	return ctx.sendText(ctx.messageCreate.ChannelID, msg, nil)
+}
+
+func (ctx *defaultContext) SendEmbed(e *discordgo.MessageEmbed) (*discordgo.Message, error) {
+	return ctx.send(ctx.messageCreate.ChannelID, &discordgo.MessageSend{Embed: e})
+}
+
+func (ctx *defaultContext) SendComplex(ms *discordgo.MessageSend) (*discordgo.Message, error) {
+	return ctx.send(ctx.messageCreate.ChannelID, ms)
+}
+
+func (ctx *defaultContext) SendFile(name string, r io.Reader) (*discordgo.Message, error) {
+	return ctx.send(ctx.messageCreate.ChannelID, &discordgo.MessageSend{
+		Files: []*discordgo.File{{Name: name, Reader: r}},
+	})
+}
+
+func (ctx *defaultContext) SendError(err error) (*discordgo.Message, error) {
+	return ctx.SendEmbed(NewEmbed().
+		SetTitle("Error").
+		SetDescription(err.Error()).
+		SetColor(ctx.bot.Theme().Error).
+		Build())
+}
+
+func (ctx *defaultContext) Reply(msg string) (*discordgo.Message, error) {
+	return ctx.sendText(ctx.messageCreate.ChannelID, msg, ctx.messageCreate.Reference())
+}
+
+func (ctx *defaultContext) DM(msg string) (*discordgo.Message, error) {
+	c, err := ctx.bot.Transport().UserChannelCreate(ctx.messageCreate.Author.ID)
+	if err != nil {
+		return nil, fmt.Errorf("could not create dm channel: %v", err)
+	}
+	return ctx.sendText(c.ID, msg, nil)
+}
+
+func (ctx *defaultContext) React(emoji string) error {
+	return ctx.bot.Transport().MessageReactionAdd(ctx.messageCreate.ChannelID, ctx.messageCreate.ID, emoji)
+}
+
+func (ctx *defaultContext) Edit(m *discordgo.Message, content string) (*discordgo.Message, error) {
+	e := discordgo.NewMessageEdit(m.ChannelID, m.ID).SetContent(content)
+	e.AllowedMentions = ctx.allowedMentions
+	return ctx.bot.Transport().ChannelMessageEditComplex(e)
 }
 
-func (ctx *defaultContext) SendEmbed(e *discordgo.MessageEmbed) {
-	_, _ = ctx.sess.ChannelMessageSendEmbed(ctx.messageCreate.ChannelID, e)
+func (ctx *defaultContext) DeleteAfter(m *discordgo.Message, d time.Duration) {
+	time.AfterFunc(d, func() {
+		if err := ctx.bot.Transport().ChannelMessageDelete(m.ChannelID, m.ID); err != nil {
+			ctx.Logger().Errorf("Could not delete message: %v", err)
+		}
+	})
+}
+
+func (ctx *defaultContext) Typing() error {
+	if err := ctx.bot.Transport().ChannelTyping(ctx.messageCreate.ChannelID); err != nil {
+		return err
+	}
+
+	go func() {
+		t := time.NewTicker(typingInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				_ = ctx.bot.Transport().ChannelTyping(ctx.messageCreate.ChannelID)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// sendText splits the message and sends the chunks to the channel
+// only the first chunk references ref, the last sent message is returned
+func (ctx *defaultContext) sendText(chanID, msg string, ref *discordgo.MessageReference) (*discordgo.Message, error) {
+	chunks := SplitMessage(msg, MaxMessageLength)
+	if max := ctx.bot.Config().MaxMessageChunks; max > 0 && len(chunks) > max {
+		return ctx.send(chanID, &discordgo.MessageSend{
+			Reference: ref,
+			Files: []*discordgo.File{{
+				Name:        "message.txt",
+				ContentType: "text/plain",
+				Reader:      strings.NewReader(msg),
+			}},
+		})
+	}
+
+	var m *discordgo.Message
+	for i, c := range chunks {
+		ms := &discordgo.MessageSend{Content: c}
+		if i == 0 {
+			ms.Reference = ref
+		}
+
+		var err error
+		m, err = ctx.send(chanID, ms)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (ctx *defaultContext) send(chanID string, ms *discordgo.MessageSend) (*discordgo.Message, error) {
+	if ms.AllowedMentions == nil {
+		ms.AllowedMentions = ctx.allowedMentions
+	}
+	return ctx.bot.Transport().ChannelMessageSendComplex(chanID, ms)
 }
 
 func (ctx *defaultContext) VoiceHandler() (VoiceHandler, error) {
-	g, err := ctx.sess.Channel(ctx.messageCreate.ChannelID)
+	g, err := ctx.bot.Transport().Channel(ctx.messageCreate.ChannelID)
 	if err != nil {
 		return nil, err
 	}
@@ -129,7 +293,7 @@ func (ctx *defaultContext) VoiceHandler() (VoiceHandler, error) {
 }
 
 func (ctx *defaultContext) PlaySound(vi VoiceItem) error {
-	c, err := ctx.sess.Channel(ctx.messageCreate.ChannelID)
+	c, err := ctx.bot.Transport().Channel(ctx.messageCreate.ChannelID)
 	if err != nil {
 		return err
 	}
@@ -143,3 +307,14 @@ func (ctx *defaultContext) PlaySound(vi VoiceItem) error {
 
 	return nil
 }
+
+func (ctx *defaultContext) WaitForReply(d time.Duration) (*discordgo.MessageCreate, error) {
+	c, cancel := context.WithTimeout(ctx.ctx, d)
+	defer cancel()
+
+	m, err := ctx.bot.WaitForReply(c, ctx.messageCreate.ChannelID, ctx.messageCreate.Author.ID)
+	if err == context.DeadlineExceeded && ctx.ctx.Err() == nil {
+		return nil, ErrReplyTimeout
+	}
+	return m, err
+}
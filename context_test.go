@@ -0,0 +1,162 @@
+package fuzzy_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/fvdveen/fuzzy"
+	"github.com/fvdveen/fuzzy/fuzzytest"
+)
+
+func TestContextHelpers(t *testing.T) {
+	tests := []struct {
+		name    string
+		chunks  int
+		handler func(fuzzy.Context)
+		check   func(*testing.T, *fuzzytest.Harness, *discordgo.Message)
+	}{
+		{
+			name: "reply",
+			handler: func(ctx fuzzy.Context) {
+				ctx.Reply("hi")
+			},
+			check: func(t *testing.T, h *fuzzytest.Harness, in *discordgo.Message) {
+				m := h.LastMessage()
+				if m.Content != "hi" || m.MessageReference == nil || m.MessageReference.MessageID != in.ID {
+					t.Fatalf("expected a reply to %s got %+v", in.ID, m)
+				}
+			},
+		},
+		{
+			name: "long reply",
+			handler: func(ctx fuzzy.Context) {
+				ctx.Reply(strings.Repeat("word ", 500))
+			},
+			check: func(t *testing.T, h *fuzzytest.Harness, in *discordgo.Message) {
+				ms := h.Messages()
+				if len(ms) != 2 {
+					t.Fatalf("expected 2 chunks got %d", len(ms))
+				}
+				if ms[0].MessageReference == nil || ms[1].MessageReference != nil {
+					t.Fatal("expected only the first chunk to reference the message")
+				}
+			},
+		},
+		{
+			name:   "too many chunks",
+			chunks: 1,
+			handler: func(ctx fuzzy.Context) {
+				ctx.SendMessage(strings.Repeat("word ", 500))
+			},
+			check: func(t *testing.T, h *fuzzytest.Harness, in *discordgo.Message) {
+				as := h.ActionsOf(fuzzytest.ActionSend)
+				if len(as) != 1 || len(as[0].Files) != 1 || as[0].Files[0].Name != "message.txt" || len(as[0].Files[0].Data) != 2500 {
+					t.Fatalf("expected the message as a file got %v", as)
+				}
+			},
+		},
+		{
+			name: "dm",
+			handler: func(ctx fuzzy.Context) {
+				ctx.DM("secret")
+			},
+			check: func(t *testing.T, h *fuzzytest.Harness, in *discordgo.Message) {
+				if m := h.LastMessage(); m.Content != "secret" || m.ChannelID != "dm-"+fuzzytest.DefaultUserID {
+					t.Fatalf("expected a dm got %+v", m)
+				}
+			},
+		},
+		{
+			name: "file",
+			handler: func(ctx fuzzy.Context) {
+				ctx.SendFile("a.txt", strings.NewReader("abc"))
+			},
+			check: func(t *testing.T, h *fuzzytest.Harness, in *discordgo.Message) {
+				as := h.ActionsOf(fuzzytest.ActionSend)
+				if len(as) != 1 || len(as[0].Files) != 1 || as[0].Files[0].Name != "a.txt" || string(as[0].Files[0].Data) != "abc" {
+					t.Fatalf("expected a.txt got %v", as)
+				}
+			},
+		},
+		{
+			name: "error",
+			handler: func(ctx fuzzy.Context) {
+				ctx.SendError(errors.New("broken"))
+			},
+			check: func(t *testing.T, h *fuzzytest.Harness, in *discordgo.Message) {
+				m := h.LastMessage()
+				if len(m.Embeds) != 1 || m.Embeds[0].Description != "broken" || m.Embeds[0].Color != h.Bot.Theme().Error {
+					t.Fatalf("expected an error embed got %+v", m)
+				}
+			},
+		},
+		{
+			name: "react",
+			handler: func(ctx fuzzy.Context) {
+				ctx.React("👍")
+			},
+			check: func(t *testing.T, h *fuzzytest.Harness, in *discordgo.Message) {
+				as := h.ActionsOf(fuzzytest.ActionReact)
+				if len(as) != 1 || as[0].Emoji != "👍" || as[0].MessageID != in.ID {
+					t.Fatalf("expected a reaction to %s got %v", in.ID, as)
+				}
+			},
+		},
+		{
+			name: "edit",
+			handler: func(ctx fuzzy.Context) {
+				m, _ := ctx.SendMessage("before")
+				ctx.Edit(m, "after")
+			},
+			check: func(t *testing.T, h *fuzzytest.Harness, in *discordgo.Message) {
+				m := h.LastMessage()
+				if cur := h.CurrentMessage(m.ID); m.Content != "before" || cur.Content != "after" {
+					t.Fatalf("expected the message to be edited got %q", cur.Content)
+				}
+			},
+		},
+		{
+			name: "delete after",
+			handler: func(ctx fuzzy.Context) {
+				m, _ := ctx.SendMessage("gone")
+				ctx.DeleteAfter(m, time.Millisecond)
+			},
+			check: func(t *testing.T, h *fuzzytest.Harness, in *discordgo.Message) {
+				m := h.LastMessage()
+				for deadline := time.Now().Add(time.Second); h.CurrentMessage(m.ID) != nil; time.Sleep(time.Millisecond) {
+					if time.Now().After(deadline) {
+						t.Fatal("expected the message to be deleted")
+					}
+				}
+			},
+		},
+		{
+			name: "typing",
+			handler: func(ctx fuzzy.Context) {
+				ctx.Typing()
+			},
+			check: func(t *testing.T, h *fuzzytest.Harness, in *discordgo.Message) {
+				if as := h.ActionsOf(fuzzytest.ActionTyping); len(as) != 1 || as[0].ChannelID != in.ChannelID {
+					t.Fatalf("expected typing in %s got %v", in.ChannelID, as)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := fuzzytest.New(fuzzy.WithConfig(&fuzzy.Config{Token: "token", Prefix: "!", LogLevel: fuzzy.LogError, MaxMessageChunks: tt.chunks}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := h.Bot.RegisterCommand(fuzzy.NewCommand("test", "", tt.handler)); err != nil {
+				t.Fatal(err)
+			}
+
+			tt.check(t, h, h.Say("!test"))
+		})
+	}
+}
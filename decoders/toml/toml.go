@@ -0,0 +1,15 @@
+// Package toml decodes TOML config files
+//
+//	fuzzy.LoadConfig(fuzzy.FromFile("bot.toml"), fuzzy.WithConfigDecoder(toml.Decode, ".toml"))
+package toml
+
+import "github.com/BurntSushi/toml"
+
+// Decode is a fuzzy.ConfigDecoder for TOML
+func Decode(b []byte) (interface{}, error) {
+	var m map[string]interface{}
+	if err := toml.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
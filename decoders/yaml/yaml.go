@@ -0,0 +1,38 @@
+// Package yaml decodes YAML config files
+//
+//	fuzzy.LoadConfig(fuzzy.FromFile("bot.yaml"), fuzzy.WithConfigDecoder(yaml.Decode, ".yaml", ".yml"))
+package yaml
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Decode is a fuzzy.ConfigDecoder for YAML
+func Decode(b []byte) (interface{}, error) {
+	var v interface{}
+	if err := yaml.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	return normalize(v), nil
+}
+
+// normalize converts the map[interface{}]interface{} values of yaml to map[string]interface{}
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, v2 := range v {
+			m[fmt.Sprint(k)] = normalize(v2)
+		}
+		return m
+	case []interface{}:
+		for i := range v {
+			v[i] = normalize(v[i])
+		}
+		return v
+	default:
+		return v
+	}
+}
@@ -0,0 +1,224 @@
+package fuzzy
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultDialogTimeout is the time a dialog waits for each reply when no timeout is set
+	DefaultDialogTimeout = 2 * time.Minute
+
+	// DialogEnd can be returned by Step.Next to end the dialog
+	DialogEnd = "\x00end"
+)
+
+// Dialog is a sequence of prompts asked to the author of a command
+// the answers are stored in the fields of a struct
+type Dialog struct {
+	Steps []*Step
+
+	// CancelKeywords cancel the dialog when given as an answer to any step
+	CancelKeywords []string
+	// Timeout is the time to wait for each answer
+	Timeout time.Duration
+	// Retries is the amount of invalid answers allowed per step
+	Retries int
+}
+
+// Step is a single prompt of a Dialog
+type Step struct {
+	// Name is the name of the step and the struct field the answer is stored in
+	// the field can also be selected with a `dialog:"name"` tag
+	Name   string
+	Prompt string
+
+	// Validate converts the answer into the value that is stored
+	// when it is nil the answer is converted to the type of the field
+	Validate func(string) (interface{}, error)
+	// Next returns the name of the step that comes after this one
+	// when it is nil or returns "" the dialog continues with the next step in Steps
+	Next func(interface{}) string
+}
+
+// NewDialog creates a new dialog with the default timeout and cancel keywords
+func NewDialog(steps ...*Step) *Dialog {
+	return &Dialog{
+		Steps:          steps,
+		CancelKeywords: []string{"cancel"},
+		Timeout:        DefaultDialogTimeout,
+		Retries:        2,
+	}
+}
+
+// Run asks all the steps of the dialog to the author of the command
+// and stores the answers in v which must be a pointer to a struct
+func (d *Dialog) Run(ctx Context, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return ErrDialogTarget
+	}
+	rv = rv.Elem()
+
+	timeout := d.Timeout
+	if timeout <= 0 {
+		timeout = DefaultDialogTimeout
+	}
+
+	for i := 0; i < len(d.Steps); {
+		s := d.Steps[i]
+		f, err := dialogField(rv, s.Name)
+		if err != nil {
+			return err
+		}
+
+		ans, err := d.ask(ctx, s, f, timeout)
+		if err != nil {
+			return err
+		}
+
+		if s.Next == nil {
+			i++
+			continue
+		}
+
+		next := s.Next(ans)
+		switch next {
+		case "":
+			i++
+		case DialogEnd:
+			return nil
+		default:
+			i = d.stepIndex(next)
+			if i < 0 {
+				return fmt.Errorf("%v: %s", ErrUnknownStep, next)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ask asks a single step until it gets a valid answer and stores it in f
+func (d *Dialog) ask(ctx Context, s *Step, f reflect.Value, timeout time.Duration) (interface{}, error) {
+	if _, err := ctx.SendMessage(s.Prompt); err != nil {
+		return nil, err
+	}
+
+	for tries := 0; ; tries++ {
+		m, err := ctx.WaitForReply(timeout)
+		if err != nil {
+			return nil, err
+		}
+
+		msg := strings.TrimSpace(m.Content)
+		for _, k := range d.CancelKeywords {
+			if strings.EqualFold(msg, k) {
+				return nil, ErrDialogCancelled
+			}
+		}
+
+		ans, err := setDialogField(f, msg, s.Validate)
+		if err == nil {
+			return ans, nil
+		}
+
+		if tries >= d.Retries {
+			return nil, ErrDialogRetries
+		}
+		if _, err := ctx.SendMessage(fmt.Sprintf("Invalid answer: %v\n%s", err, s.Prompt)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (d *Dialog) stepIndex(name string) int {
+	for i, s := range d.Steps {
+		if s.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// dialogField finds the field for the step name by its dialog tag or its name
+func dialogField(v reflect.Value, name string) (reflect.Value, error) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("dialog") == name {
+			return v.Field(i), nil
+		}
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if strings.EqualFold(t.Field(i).Name, name) && t.Field(i).PkgPath == "" {
+			return v.Field(i), nil
+		}
+	}
+	return reflect.Value{}, fmt.Errorf("no field for dialog step %s in %s", name, t)
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// setDialogField stores the answer in f and returns the stored value
+func setDialogField(f reflect.Value, ans string, validate func(string) (interface{}, error)) (interface{}, error) {
+	if validate != nil {
+		x, err := validate(ans)
+		if err != nil {
+			return nil, err
+		}
+		xv := reflect.ValueOf(x)
+		if !xv.IsValid() || !xv.Type().ConvertibleTo(f.Type()) {
+			return nil, fmt.Errorf("can not store %T in %s", x, f.Type())
+		}
+		f.Set(xv.Convert(f.Type()))
+		return x, nil
+	}
+
+	if f.Type() == durationType {
+		d, err := time.ParseDuration(ans)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a duration", ans)
+		}
+		f.SetInt(int64(d))
+		return d, nil
+	}
+
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(ans)
+	case reflect.Bool:
+		switch strings.ToLower(ans) {
+		case "y", "yes", "true":
+			f.SetBool(true)
+		case "n", "no", "false":
+			f.SetBool(false)
+		default:
+			return nil, fmt.Errorf("%q is not yes or no", ans)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(ans, 10, f.Type().Bits())
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", ans)
+		}
+		f.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := strconv.ParseUint(ans, 10, f.Type().Bits())
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a positive number", ans)
+		}
+		f.SetUint(i)
+	case reflect.Float32, reflect.Float64:
+		x, err := strconv.ParseFloat(ans, f.Type().Bits())
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", ans)
+		}
+		f.SetFloat(x)
+	default:
+		return nil, fmt.Errorf("unsupported field type %s", f.Type())
+	}
+
+	return f.Interface(), nil
+}
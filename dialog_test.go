@@ -0,0 +1,178 @@
+package fuzzy_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fvdveen/fuzzy"
+	"github.com/fvdveen/fuzzy/fuzzytest"
+)
+
+type profile struct {
+	Name    string
+	Age     int
+	Pet     bool
+	PetKind string `dialog:"pet kind"`
+}
+
+// runDialog runs the dialog in a command and answers it, it returns the answers and the prompts of the bot
+func runDialog(t *testing.T, d *fuzzy.Dialog, target func(*profile) interface{}, answers ...string) (profile, []string, error) {
+	t.Helper()
+
+	h, err := fuzzytest.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var p profile
+	res := make(chan error, 1)
+	if err := h.Bot.RegisterCommand(fuzzy.NewCommand("dialog", "", func(ctx fuzzy.Context) {
+		res <- d.Run(ctx, target(&p))
+	})); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, l := range append([]string{"!dialog"}, answers...) {
+		if _, err := h.SendIdle(fuzzytest.DefaultChannelID, fuzzytest.DefaultUserID, l); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	select {
+	case err = <-res:
+	case <-time.After(time.Second):
+		t.Fatal("expected the dialog to end")
+	}
+
+	var prompts []string
+	for _, m := range h.Messages() {
+		prompts = append(prompts, m.Content)
+	}
+	return p, prompts, err
+}
+
+func TestDialog(t *testing.T) {
+	name := &fuzzy.Step{Name: "name", Prompt: "name?"}
+	age := &fuzzy.Step{Name: "age", Prompt: "age?"}
+	upper := &fuzzy.Step{Name: "name", Prompt: "name?", Validate: func(s string) (interface{}, error) {
+		if s == "" {
+			return nil, fmt.Errorf("no name")
+		}
+		return strings.ToUpper(s), nil
+	}}
+	pet := &fuzzy.Step{Name: "pet", Prompt: "pet?", Next: func(v interface{}) string {
+		if !v.(bool) {
+			return fuzzy.DialogEnd
+		}
+		return "pet kind"
+	}}
+	kind := &fuzzy.Step{Name: "pet kind", Prompt: "kind?"}
+	lost := &fuzzy.Step{Name: "pet", Prompt: "pet?", Next: func(interface{}) string {
+		return "unknown"
+	}}
+
+	pointer := func(p *profile) interface{} { return p }
+
+	tests := []struct {
+		name    string
+		dialog  *fuzzy.Dialog
+		target  func(*profile) interface{}
+		answers []string
+		profile profile
+		err     error
+		prompts []string
+	}{
+		{
+			name:    "steps",
+			dialog:  fuzzy.NewDialog(name, age),
+			answers: []string{"gopher", "11"},
+			profile: profile{Name: "gopher", Age: 11},
+			prompts: []string{"name?", "age?"},
+		},
+		{
+			name:    "validate",
+			dialog:  fuzzy.NewDialog(upper),
+			answers: []string{"gopher"},
+			profile: profile{Name: "GOPHER"},
+			prompts: []string{"name?"},
+		},
+		{
+			name:    "retry",
+			dialog:  fuzzy.NewDialog(age),
+			answers: []string{"old", "11"},
+			profile: profile{Age: 11},
+			prompts: []string{"age?", "Invalid answer: \"old\" is not a number\nage?"},
+		},
+		{
+			name:    "too many retries",
+			dialog:  fuzzy.NewDialog(age),
+			answers: []string{"old", "older", "oldest"},
+			err:     fuzzy.ErrDialogRetries,
+			prompts: []string{"age?", "Invalid answer: \"old\" is not a number\nage?", "Invalid answer: \"older\" is not a number\nage?"},
+		},
+		{
+			name:    "cancel",
+			dialog:  fuzzy.NewDialog(name, age),
+			answers: []string{"gopher", "Cancel"},
+			profile: profile{Name: "gopher"},
+			err:     fuzzy.ErrDialogCancelled,
+			prompts: []string{"name?", "age?"},
+		},
+		{
+			name:    "branch",
+			dialog:  fuzzy.NewDialog(pet, name, kind),
+			answers: []string{"yes", "cat"},
+			profile: profile{Pet: true, PetKind: "cat"},
+			prompts: []string{"pet?", "kind?"},
+		},
+		{
+			name:    "end",
+			dialog:  fuzzy.NewDialog(pet, name, kind),
+			answers: []string{"no"},
+			prompts: []string{"pet?"},
+		},
+		{
+			name:    "unknown step",
+			dialog:  fuzzy.NewDialog(lost, name),
+			answers: []string{"yes"},
+			profile: profile{Pet: true},
+			err:     fuzzy.ErrUnknownStep,
+			prompts: []string{"pet?"},
+		},
+		{
+			name:    "timeout",
+			dialog:  &fuzzy.Dialog{Steps: []*fuzzy.Step{name}, Timeout: 10 * time.Millisecond},
+			err:     fuzzy.ErrReplyTimeout,
+			prompts: []string{"name?"},
+		},
+		{
+			name:   "target",
+			dialog: fuzzy.NewDialog(name),
+			target: func(p *profile) interface{} { return *p },
+			err:    fuzzy.ErrDialogTarget,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := tt.target
+			if target == nil {
+				target = pointer
+			}
+
+			p, prompts, err := runDialog(t, tt.dialog, target, tt.answers...)
+			if (err == nil) != (tt.err == nil) || err != nil && !strings.HasPrefix(err.Error(), tt.err.Error()) {
+				t.Fatalf("expected error %v got %v", tt.err, err)
+			}
+			if p != tt.profile {
+				t.Fatalf("expected answers %+v got %+v", tt.profile, p)
+			}
+			if !reflect.DeepEqual(prompts, tt.prompts) {
+				t.Fatalf("expected prompts %q got %q", tt.prompts, prompts)
+			}
+		})
+	}
+}
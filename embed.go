@@ -0,0 +1,238 @@
+package fuzzy
+
+import (
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Discord's embed limits
+const (
+	EmbedTitleLimit       = 256
+	EmbedDescriptionLimit = 4096
+	EmbedFieldLimit       = 25
+	EmbedFieldNameLimit   = 256
+	EmbedFieldValueLimit  = 1024
+	EmbedFooterLimit      = 2048
+	EmbedAuthorLimit      = 256
+	EmbedTotalLimit       = 6000
+)
+
+// Theme holds the colors used for embeds sent by the bot
+type Theme struct {
+	Primary int
+	Success int
+	Warning int
+	Error   int
+}
+
+// DefaultTheme is the theme used when the bot has no theme set
+func DefaultTheme() Theme {
+	return Theme{
+		Primary: 0x5865f2,
+		Success: 0x57f287,
+		Warning: 0xfee75c,
+		Error:   0xed4245,
+	}
+}
+
+// EmbedBuilder builds embeds that fit within discord's limits
+type EmbedBuilder struct {
+	e *discordgo.MessageEmbed
+}
+
+// NewEmbed creates a new embed builder
+func NewEmbed() *EmbedBuilder {
+	return &EmbedBuilder{e: &discordgo.MessageEmbed{}}
+}
+
+// SetTitle sets the title of the embed
+func (b *EmbedBuilder) SetTitle(t string) *EmbedBuilder {
+	b.e.Title = t
+	return b
+}
+
+// SetDescription sets the description of the embed
+func (b *EmbedBuilder) SetDescription(d string) *EmbedBuilder {
+	b.e.Description = d
+	return b
+}
+
+// SetURL sets the url the title links to
+func (b *EmbedBuilder) SetURL(u string) *EmbedBuilder {
+	b.e.URL = u
+	return b
+}
+
+// SetColor sets the color of the embed
+func (b *EmbedBuilder) SetColor(c int) *EmbedBuilder {
+	b.e.Color = c
+	return b
+}
+
+// SetTimestamp sets the timestamp of the embed
+func (b *EmbedBuilder) SetTimestamp(t time.Time) *EmbedBuilder {
+	b.e.Timestamp = t.Format(time.RFC3339)
+	return b
+}
+
+// SetAuthor sets the author of the embed
+func (b *EmbedBuilder) SetAuthor(name, iconURL, u string) *EmbedBuilder {
+	b.e.Author = &discordgo.MessageEmbedAuthor{
+		Name:    name,
+		IconURL: iconURL,
+		URL:     u,
+	}
+	return b
+}
+
+// SetFooter sets the footer of the embed
+func (b *EmbedBuilder) SetFooter(text, iconURL string) *EmbedBuilder {
+	b.e.Footer = &discordgo.MessageEmbedFooter{
+		Text:    text,
+		IconURL: iconURL,
+	}
+	return b
+}
+
+// SetThumbnail sets the thumbnail of the embed
+func (b *EmbedBuilder) SetThumbnail(u string) *EmbedBuilder {
+	b.e.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: u}
+	return b
+}
+
+// SetImage sets the image of the embed
+func (b *EmbedBuilder) SetImage(u string) *EmbedBuilder {
+	b.e.Image = &discordgo.MessageEmbedImage{URL: u}
+	return b
+}
+
+// AddField adds a field to the embed
+func (b *EmbedBuilder) AddField(name, value string, inline bool) *EmbedBuilder {
+	b.e.Fields = append(b.e.Fields, &discordgo.MessageEmbedField{
+		Name:   name,
+		Value:  value,
+		Inline: inline,
+	})
+	return b
+}
+
+// Length returns the amount of characters the embed counts towards discord's total limit
+func (b *EmbedBuilder) Length() int {
+	return embedLength(b.e)
+}
+
+// FieldCount returns the amount of fields added to the embed
+func (b *EmbedBuilder) FieldCount() int {
+	return len(b.e.Fields)
+}
+
+// embedCheck is a single text in an embed with its limit
+type embedCheck struct {
+	name  string
+	value string
+	limit int
+}
+
+// Validate checks if the embed fits within discord's limits
+func (b *EmbedBuilder) Validate() error {
+	e := b.e
+	checks := []embedCheck{
+		{"title", e.Title, EmbedTitleLimit},
+		{"description", e.Description, EmbedDescriptionLimit},
+	}
+	if e.Author != nil {
+		checks = append(checks, embedCheck{"author", e.Author.Name, EmbedAuthorLimit})
+	}
+	if e.Footer != nil {
+		checks = append(checks, embedCheck{"footer", e.Footer.Text, EmbedFooterLimit})
+	}
+	for i, f := range e.Fields {
+		checks = append(checks,
+			embedCheck{fmt.Sprintf("field %d name", i), f.Name, EmbedFieldNameLimit},
+			embedCheck{fmt.Sprintf("field %d value", i), f.Value, EmbedFieldValueLimit},
+		)
+	}
+
+	for _, c := range checks {
+		if l := utf8.RuneCountInString(c.value); l > c.limit {
+			return fmt.Errorf("%v: %s has %d characters, the limit is %d", ErrEmbedLimit, c.name, l, c.limit)
+		}
+	}
+	if len(e.Fields) > EmbedFieldLimit {
+		return fmt.Errorf("%v: embed has %d fields, the limit is %d", ErrEmbedLimit, len(e.Fields), EmbedFieldLimit)
+	}
+	if l := embedLength(e); l > EmbedTotalLimit {
+		return fmt.Errorf("%v: embed has %d characters, the limit is %d", ErrEmbedLimit, l, EmbedTotalLimit)
+	}
+
+	return nil
+}
+
+// Build creates the embed, everything that exceeds discord's limits is truncated
+func (b *EmbedBuilder) Build() *discordgo.MessageEmbed {
+	e := *b.e
+	e.Title = truncate(e.Title, EmbedTitleLimit)
+	e.Description = truncate(e.Description, EmbedDescriptionLimit)
+	if e.Author != nil {
+		a := *e.Author
+		a.Name = truncate(a.Name, EmbedAuthorLimit)
+		e.Author = &a
+	}
+	if e.Footer != nil {
+		f := *e.Footer
+		f.Text = truncate(f.Text, EmbedFooterLimit)
+		e.Footer = &f
+	}
+
+	if len(e.Fields) > EmbedFieldLimit {
+		e.Fields = e.Fields[:EmbedFieldLimit]
+	}
+	fs := make([]*discordgo.MessageEmbedField, len(e.Fields))
+	for i, f := range e.Fields {
+		fs[i] = &discordgo.MessageEmbedField{
+			Name:   truncate(f.Name, EmbedFieldNameLimit),
+			Value:  truncate(f.Value, EmbedFieldValueLimit),
+			Inline: f.Inline,
+		}
+	}
+	e.Fields = fs
+
+	for embedLength(&e) > EmbedTotalLimit && len(e.Fields) > 0 {
+		e.Fields = e.Fields[:len(e.Fields)-1]
+	}
+	if l := embedLength(&e); l > EmbedTotalLimit {
+		e.Description = truncate(e.Description, utf8.RuneCountInString(e.Description)-(l-EmbedTotalLimit))
+	}
+
+	return &e
+}
+
+// embedLength is the amount of characters discord counts towards the total embed limit
+func embedLength(e *discordgo.MessageEmbed) int {
+	l := utf8.RuneCountInString(e.Title) + utf8.RuneCountInString(e.Description)
+	if e.Author != nil {
+		l += utf8.RuneCountInString(e.Author.Name)
+	}
+	if e.Footer != nil {
+		l += utf8.RuneCountInString(e.Footer.Text)
+	}
+	for _, f := range e.Fields {
+		l += utf8.RuneCountInString(f.Name) + utf8.RuneCountInString(f.Value)
+	}
+	return l
+}
+
+// truncate shortens s to at most n characters ending in an ellipsis
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	if n <= 0 {
+		return ""
+	}
+	rs := []rune(s)
+	return string(rs[:n-1]) + "…"
+}
@@ -0,0 +1,45 @@
+package fuzzy_test
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/fvdveen/fuzzy"
+)
+
+func TestEmbedBuilderTruncates(t *testing.T) {
+	b := fuzzy.NewEmbed().
+		SetTitle(strings.Repeat("t", 300)).
+		SetDescription(strings.Repeat("d", 5000))
+	for i := 0; i < 30; i++ {
+		b.AddField(strings.Repeat("n", 300), strings.Repeat("v", 1100), false)
+	}
+
+	if err := b.Validate(); err == nil {
+		t.Error("expected validation error for oversized embed")
+	}
+
+	e := b.Build()
+	if l := utf8.RuneCountInString(e.Title); l != fuzzy.EmbedTitleLimit {
+		t.Errorf("expected title of length %d got: %d", fuzzy.EmbedTitleLimit, l)
+	}
+	if len(e.Fields) > fuzzy.EmbedFieldLimit {
+		t.Errorf("expected at most %d fields got: %d", fuzzy.EmbedFieldLimit, len(e.Fields))
+	}
+
+	total := utf8.RuneCountInString(e.Title) + utf8.RuneCountInString(e.Description)
+	for _, f := range e.Fields {
+		total += utf8.RuneCountInString(f.Name) + utf8.RuneCountInString(f.Value)
+	}
+	if total > fuzzy.EmbedTotalLimit {
+		t.Errorf("expected at most %d characters got: %d", fuzzy.EmbedTotalLimit, total)
+	}
+}
+
+func TestEmbedBuilderValid(t *testing.T) {
+	b := fuzzy.NewEmbed().SetTitle("title").SetDescription("description").AddField("name", "value", true)
+	if err := b.Validate(); err != nil {
+		t.Error(err)
+	}
+}
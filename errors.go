@@ -14,4 +14,94 @@ var (
 
 	// ErrVoiceHandlerNotExists is used when there is no voice handler for the given guild
 	ErrVoiceHandlerNotExists = errors.New("voice handler doesn't exist")
+
+	// ErrQueueIndex is used when an index is outside of the queue of a voice handler
+	ErrQueueIndex = errors.New("index out of queue bounds")
+
+	// ErrNothingPlaying is used when nothing is playing in the guild
+	ErrNothingPlaying = errors.New("nothing is playing")
+
+	// ErrReplyTimeout is used when no reply was received in time
+	ErrReplyTimeout = errors.New("timed out waiting for reply")
+
+	// ErrDialogCancelled is used when the user cancels a dialog
+	ErrDialogCancelled = errors.New("dialog cancelled")
+
+	// ErrDialogRetries is used when the user gave too many invalid answers to a dialog step
+	ErrDialogRetries = errors.New("too many invalid answers")
+
+	// ErrDialogTarget is used when a dialog is run with something other than a pointer to a struct
+	ErrDialogTarget = errors.New("dialog target must be a pointer to a struct")
+
+	// ErrUnknownStep is used when a dialog branches to a step that does not exist
+	ErrUnknownStep = errors.New("unknown dialog step")
+
+	// ErrEmbedLimit is used when an embed exceeds one of discord's limits
+	ErrEmbedLimit = errors.New("embed exceeds discord limit")
+
+	// ErrUnknownCommand is used when there is no command with the given name
+	ErrUnknownCommand = errors.New("unknown command")
+
+	// ErrCommandDisabled is used when a command is disabled in the guild or channel
+	ErrCommandDisabled = errors.New("command is disabled")
+
+	// ErrModuleLoaded is used when a module with the same name is already loaded
+	ErrModuleLoaded = errors.New("module is already loaded")
+
+	// ErrUnknownModule is used when there is no loaded module with the given name
+	ErrUnknownModule = errors.New("unknown module")
+
+	// ErrInvalidEventHandler is used when a handler subscribed to the event bus has the wrong signature
+	ErrInvalidEventHandler = errors.New("event handler must be an EventHandler or a func(*Bot, T) with an optional error result")
+
+	// ErrDuplicateJob is used when multiple jobs with the same name are scheduled
+	ErrDuplicateJob = errors.New("2 or more jobs with the same name")
+
+	// ErrInvalidInterval is used when a schedule is created with an interval that is not positive
+	ErrInvalidInterval = errors.New("interval must be positive")
+
+	// ErrUnknownJob is used when there is no job with the given name
+	ErrUnknownJob = errors.New("unknown job")
+
+	// ErrGuildOnly is used when a guild only command is used outside of a guild
+	ErrGuildOnly = errors.New("this command can only be used in a server")
+
+	// ErrMissingPermissions is used when the user does not have the permissions required by a command
+	ErrMissingPermissions = errors.New("you do not have the permissions required for this command")
+
+	// ErrNotFound is used when a key is not in a Store
+	ErrNotFound = errors.New("key not found")
+
+	// ErrNoGuild is used when the store of a guild is used without a guild, like in direct messages
+	ErrNoGuild = errors.New("no guild")
+
+	// ErrUnknownSetting is used when there is no setting with the given name
+	ErrUnknownSetting = errors.New("unknown setting")
+
+	// ErrDuplicateSetting is used when multiple settings with the same name are registered
+	ErrDuplicateSetting = errors.New("2 or more settings with the same name")
+
+	// ErrInvalidSetting is used when a value is not valid for a setting
+	ErrInvalidSetting = errors.New("invalid value")
+
+	// ErrNotDJ is used when a user without the DJ role uses a command that requires it
+	ErrNotDJ = errors.New("you need the DJ role to use this command")
+
+	// ErrInvalidConfig is used when a loaded config is not valid
+	ErrInvalidConfig = errors.New("invalid config")
+
+	// ErrUnknownConfigFormat is used when the format of a config file is not supported
+	ErrUnknownConfigFormat = errors.New("unknown config file format")
+
+	// ErrUnknownLogLevel is used when a log level name is not recognized
+	ErrUnknownLogLevel = errors.New("unknown log level")
+
+	// ErrOwnerOnly is used when a user who does not own the bot uses an owner only command
+	ErrOwnerOnly = errors.New("this command can only be used by the owners of the bot")
+
+	// ErrNoConfigFile is used when a config is watched without a config file
+	ErrNoConfigFile = errors.New("no config file")
+
+	// ErrInvalidConsoleOption is used when the console environment variable could not be parsed
+	ErrInvalidConsoleOption = errors.New("invalid console option")
 )
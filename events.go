@@ -0,0 +1,230 @@
+package fuzzy
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// frameworkPriority is the priority of the bot's own event handlers so they run after all others
+const frameworkPriority = -1000
+
+var (
+	botType   = reflect.TypeOf((*Bot)(nil))
+	errorType = reflect.TypeOf((*error)(nil)).Elem()
+	anyType   = reflect.TypeOf((*interface{})(nil)).Elem()
+)
+
+// CommandExecuted is published after a command has been handled
+type CommandExecuted struct {
+	Context  Context
+	Duration time.Duration
+}
+
+// CommandFailed is published when a command did not pass its checks or panicked
+type CommandFailed struct {
+	Context Context
+	Err     error
+}
+
+// VoiceTrackStarted is published when a voice handler starts playing an item
+type VoiceTrackStarted struct {
+	GuildID string
+	Item    VoiceItem
+}
+
+// VoiceTrackEnded is published when a voice handler is done playing an item
+type VoiceTrackEnded struct {
+	GuildID string
+	Item    VoiceItem
+	Err     error
+}
+
+// ConfigReloaded is published when the config of the bot has changed
+type ConfigReloaded struct {
+	Old     *Config
+	New     *Config
+	Changes []string
+}
+
+// EventHandler handles events published on the EventBus
+type EventHandler interface {
+	HandleEvent(*Bot, interface{}) error
+}
+
+// EventHandlerFunc implements EventHandler on a function
+type EventHandlerFunc func(*Bot, interface{}) error
+
+// HandleEvent implements EventHandler
+func (f EventHandlerFunc) HandleEvent(b *Bot, e interface{}) error {
+	return f(b, e)
+}
+
+// EventMiddleware wraps the handlers of the EventBus
+type EventMiddleware func(EventHandler) EventHandler
+
+// SubscribeOption sets an option of a Subscription
+type SubscribeOption func(*Subscription)
+
+// Async runs the handler in its own goroutine instead of waiting for it
+func Async() SubscribeOption {
+	return func(s *Subscription) {
+		s.async = true
+	}
+}
+
+// Priority sets the order of the handler, handlers with a higher priority are called first
+func Priority(p int) SubscribeOption {
+	return func(s *Subscription) {
+		s.priority = p
+	}
+}
+
+// Subscription is a handler subscribed to the EventBus
+type Subscription struct {
+	bus      *EventBus
+	typ      reflect.Type
+	handler  EventHandler
+	priority int
+	async    bool
+}
+
+// Unsubscribe removes the handler from the EventBus
+func (s *Subscription) Unsubscribe() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+
+	for i, s2 := range s.bus.subs {
+		if s2 == s {
+			s.bus.subs = append(s.bus.subs[:i:i], s.bus.subs[i+1:]...)
+			return
+		}
+	}
+}
+
+// EventBus passes discord and framework events to the subscribed handlers
+type EventBus struct {
+	bot *Bot
+
+	mu         sync.RWMutex
+	subs       []*Subscription
+	middleware []EventMiddleware
+}
+
+// NewEventBus creates a new event bus for the bot
+func NewEventBus(b *Bot) *EventBus {
+	return &EventBus{bot: b}
+}
+
+// Subscribe adds a handler for a type of event
+// h is either an EventHandler which receives all events or a function like
+// func(*Bot, *discordgo.MessageCreate) which may also return an error
+// when the event type is an interface the handler receives all events implementing it
+func (bus *EventBus) Subscribe(h interface{}, opts ...SubscribeOption) (*Subscription, error) {
+	typ, eh, err := eventHandlerFor(h)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Subscription{
+		bus:     bus,
+		typ:     typ,
+		handler: eh,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+
+	subs := append(bus.subs[:len(bus.subs):len(bus.subs)], s)
+	sort.SliceStable(subs, func(i, j int) bool {
+		return subs[i].priority > subs[j].priority
+	})
+	bus.subs = subs
+
+	return s, nil
+}
+
+// Use adds middleware to all handlers of the bus
+func (bus *EventBus) Use(ms ...EventMiddleware) {
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+
+	bus.middleware = append(bus.middleware, ms...)
+}
+
+// Publish passes the event to all handlers subscribed to it
+// it returns when all handlers that are not async are done
+func (bus *EventBus) Publish(e interface{}) {
+	if e == nil {
+		return
+	}
+	et := reflect.TypeOf(e)
+
+	bus.mu.RLock()
+	subs := bus.subs
+	ms := bus.middleware
+	bus.mu.RUnlock()
+
+	for _, s := range subs {
+		if s.typ != et && !(s.typ.Kind() == reflect.Interface && et.Implements(s.typ)) {
+			continue
+		}
+
+		h := s.handler
+		for i := range ms {
+			h = ms[len(ms)-1-i](h)
+		}
+
+		if s.async {
+			go bus.handle(h, e)
+		} else {
+			bus.handle(h, e)
+		}
+	}
+}
+
+func (bus *EventBus) handle(h EventHandler, e interface{}) {
+	if err := h.HandleEvent(bus.bot, e); err != nil {
+		bus.bot.Generator().Logger(bus.bot.Config().LogLevel).Errorf("Could not handle %T: %v", e, err)
+	}
+}
+
+// eventHandlerFor creates an EventHandler for h and gives the type of events it handles
+func eventHandlerFor(h interface{}) (reflect.Type, EventHandler, error) {
+	if eh, ok := h.(EventHandler); ok {
+		return anyType, eh, nil
+	}
+
+	v := reflect.ValueOf(h)
+	if !v.IsValid() || (v.Kind() == reflect.Func && v.IsNil()) {
+		return nil, nil, ErrInvalidEventHandler
+	}
+	t := v.Type()
+	if t.Kind() != reflect.Func || t.NumIn() != 2 || t.In(0) != botType ||
+		t.NumOut() > 1 || (t.NumOut() == 1 && t.Out(0) != errorType) {
+		return nil, nil, ErrInvalidEventHandler
+	}
+
+	return t.In(1), EventHandlerFunc(func(b *Bot, e interface{}) error {
+		out := v.Call([]reflect.Value{reflect.ValueOf(b), reflect.ValueOf(e)})
+		if len(out) == 1 && !out[0].IsNil() {
+			return out[0].Interface().(error)
+		}
+		return nil
+	}), nil
+}
+
+// Inject publishes the event as if it was received from discord
+// it returns when all handlers that are not async are done
+func (b *Bot) Inject(e interface{}) {
+	b.events.Publish(e)
+}
+
+// Events returns the bot's event bus
+func (b *Bot) Events() *EventBus {
+	return b.events
+}
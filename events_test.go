@@ -0,0 +1,93 @@
+package fuzzy_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/fvdveen/fuzzy"
+)
+
+type testEvent struct {
+	n int
+}
+
+func TestEventBus(t *testing.T) {
+	b, err := fuzzy.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bus := fuzzy.NewEventBus(b)
+
+	var got []string
+	s, err := bus.Subscribe(func(_ *fuzzy.Bot, e *testEvent) {
+		got = append(got, "low")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bus.Subscribe(func(_ *fuzzy.Bot, e *testEvent) error {
+		got = append(got, "high")
+		return errors.New("handled with error")
+	}, fuzzy.Priority(10)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bus.Subscribe(func(_ *fuzzy.Bot, e *fuzzy.CommandFailed) {
+		got = append(got, "other")
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	bus.Publish(&testEvent{n: 1})
+	s.Unsubscribe()
+	bus.Publish(&testEvent{n: 2})
+
+	if exp := []string{"high", "low", "high"}; !reflect.DeepEqual(got, exp) {
+		t.Errorf("expected: %v got: %v", exp, got)
+	}
+}
+
+func TestEventBusInvalidHandler(t *testing.T) {
+	b, err := fuzzy.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bus := fuzzy.NewEventBus(b)
+
+	for _, h := range []interface{}{
+		func(e *testEvent) {},
+		func(_ *fuzzy.Bot, e *testEvent) int { return 0 },
+		"not a function",
+		nil,
+		(func(*fuzzy.Bot, *testEvent))(nil),
+	} {
+		if _, err := bus.Subscribe(h); err != fuzzy.ErrInvalidEventHandler {
+			t.Errorf("expected: %v got: %v", fuzzy.ErrInvalidEventHandler, err)
+		}
+	}
+}
+
+func TestRegisterHandler(t *testing.T) {
+	b, err := fuzzy.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n := 0
+	h := func(_ *fuzzy.Bot, e *testEvent) { n += e.n }
+	if err := b.RegisterHandler(h, nil); err != fuzzy.ErrInvalidEventHandler {
+		t.Fatalf("expected: %v got: %v", fuzzy.ErrInvalidEventHandler, err)
+	}
+	b.Inject(&testEvent{n: 1})
+	if n != 0 {
+		t.Fatal("expected no handlers to be registered")
+	}
+
+	if err := b.RegisterHandler(h); err != nil {
+		t.Fatal(err)
+	}
+	b.Inject(&testEvent{n: 1})
+	if n != 1 {
+		t.Fatalf("expected the handler to be called once got %d", n)
+	}
+}
@@ -1,27 +1,35 @@
 package main
 
 import (
-	"flag"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
 
 	"github.com/fvdveen/fuzzy"
+	"github.com/fvdveen/fuzzy/decoders/yaml"
 )
 
 func main() {
-	token := flag.String("token", "", "The discord bot token")
-	prefix := flag.String("prefix", "$", "The bots prefix")
-	flag.Parse()
+	sources := []fuzzy.ConfigOption{
+		fuzzy.WithDefaults(fuzzy.Config{Prefix: "$", LogLevel: fuzzy.LogInfo}),
+		fuzzy.FromFile("pingpong.yaml"),
+		fuzzy.WithConfigDecoder(yaml.Decode, ".yaml", ".yml"),
+		fuzzy.FromEnv(fuzzy.DefaultEnvPrefix),
+		fuzzy.FromFlags(os.Args[1:]),
+	}
+	conf, err := fuzzy.LoadConfig(sources...)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	bot, err := fuzzy.New(
-		fuzzy.WithToken(*token),
-		fuzzy.WithPrefix(*prefix),
-	)
+	bot, err := fuzzy.New(fuzzy.WithConfig(conf))
 	if err != nil {
 		log.Fatal(err)
 	}
+	if err := bot.WatchConfig(sources...); err != nil {
+		log.Fatal(err)
+	}
 
 	_ = bot.RegisterCommand(
 		fuzzy.HelpCommand("ping-pong bot", "An example ping-pong bot"),
@@ -33,6 +41,14 @@ func main() {
 		}),
 	)
 
+	// fuzzy-docs and fuzzy-console run the bot as a tool instead of connecting to discord
+	if ok, err := bot.RunTool(); ok {
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	err = bot.Open()
 	if err != nil {
 		log.Fatal(err)
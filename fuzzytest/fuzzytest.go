@@ -0,0 +1,422 @@
+// Package fuzzytest runs a fuzzy bot against a fake discord so commands can be tested without a connection
+//
+// The harness answers the REST requests of the bot from a fake state and records everything the bot does
+//
+//	h, err := fuzzytest.New()
+//	...
+//	_ = h.Bot.RegisterCommand(pingCommand)
+//	h.Say("!ping")
+//	if m := h.LastMessage(); m == nil || m.Content != "pong" {
+//		t.Fatal("expected pong")
+//	}
+package fuzzytest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/fvdveen/fuzzy"
+)
+
+// IDs of the fake discord created by New
+const (
+	BotID                 = "1000"
+	DefaultGuildID        = "2000"
+	DefaultChannelID      = "3000"
+	DefaultVoiceChannelID = "3001"
+	DefaultUserID         = "4000"
+)
+
+// DefaultPermissions are the permissions of the everyone role in guilds added to the harness
+const DefaultPermissions = discordgo.PermissionViewChannel | discordgo.PermissionSendMessages |
+	discordgo.PermissionReadMessageHistory | discordgo.PermissionAddReactions |
+	discordgo.PermissionEmbedLinks | discordgo.PermissionAttachFiles |
+	discordgo.PermissionVoiceConnect | discordgo.PermissionVoiceSpeak
+
+// IdleTimeout is how long SendIdle and ReactIdle wait for the bot to become idle
+var IdleTimeout = 5 * time.Second
+
+// ErrNotIdle is used when the bot is still busy after IdleTimeout
+var ErrNotIdle = errors.New("bot did not become idle")
+
+// ActionType is the kind of thing the bot did
+type ActionType string
+
+// the actions recorded by the harness
+const (
+	ActionSend           ActionType = "send"
+	ActionEdit           ActionType = "edit"
+	ActionDelete         ActionType = "delete"
+	ActionReact          ActionType = "react"
+	ActionUnreact        ActionType = "unreact"
+	ActionClearReactions ActionType = "clear_reactions"
+	ActionTyping         ActionType = "typing"
+	ActionVoiceJoin      ActionType = "voice_join"
+	ActionVoiceLeave     ActionType = "voice_leave"
+	ActionVoicePlay      ActionType = "voice_play"
+	ActionVoiceSkip      ActionType = "voice_skip"
+	ActionVoiceStop      ActionType = "voice_stop"
+	ActionVoicePause     ActionType = "voice_pause"
+	ActionVoiceResume    ActionType = "voice_resume"
+	ActionVoiceLoop      ActionType = "voice_loop"
+	ActionVoiceRepeat    ActionType = "voice_repeat"
+)
+
+// File is a file uploaded by the bot
+type File struct {
+	Name string
+	Data []byte
+}
+
+// Action is something the bot did
+type Action struct {
+	Type      ActionType
+	GuildID   string
+	ChannelID string
+	MessageID string
+	// Message is the message as it was sent or edited
+	Message *discordgo.Message
+	Files   []File
+	Emoji   string
+	UserID  string
+	// Frames are the opus frames of a played voice item
+	Frames [][]byte
+	Time   time.Time
+}
+
+// Harness is a bot connected to a fake discord
+type Harness struct {
+	Bot     *fuzzy.Bot
+	BotUser *discordgo.User
+	// Clock is the time of the fake voice connections
+	Clock *Clock
+
+	mu         sync.Mutex
+	nextID     int64
+	actions    []Action
+	messages   map[string]*discordgo.Message
+	users      map[string]*discordgo.User
+	voiceConns map[string]*VoiceConnection
+	joinErr    error
+}
+
+// New creates a bot with the options and connects it to a fake discord
+// the fake discord has a guild with a text channel, a voice channel and a user
+// played voice items are recorded right away, use UseDefaultVoiceHandler to play them on fake voice connections
+func New(opts ...fuzzy.OptionFunc) (*Harness, error) {
+	h := &Harness{
+		BotUser:    &discordgo.User{ID: BotID, Username: "fuzzy", Discriminator: "0000", Bot: true},
+		Clock:      NewClock(time.Unix(0, 0).UTC()),
+		nextID:     10000,
+		messages:   make(map[string]*discordgo.Message),
+		users:      make(map[string]*discordgo.User),
+		voiceConns: make(map[string]*VoiceConnection),
+	}
+
+	opts = append([]fuzzy.OptionFunc{
+		fuzzy.WithConfig(&fuzzy.Config{Token: "fuzzytest", Prefix: "!", LogLevel: fuzzy.LogError}),
+	}, opts...)
+	// the transport is created from the generator in fuzzy.New so it is set after the options of the caller
+	// the generator of the caller may be shared so the harness changes a copy
+	opts = append(opts, func(b *fuzzy.Bot) {
+		g := *b.Generator()
+		g.SetTransportGenerator(h.transportGenerator)
+		g.SetVoiceHandlerGenerator(h.voiceHandlerGenerator)
+		fuzzy.WithGenerator(&g)(b)
+	})
+	b, err := fuzzy.New(opts...)
+	if err != nil {
+		return nil, err
+	}
+	h.Bot = b
+
+	s := b.Session()
+	s.Client = &http.Client{Transport: h}
+	s.State.User = h.BotUser
+
+	if _, err := h.AddGuild(DefaultGuildID, "fuzzytest"); err != nil {
+		return nil, err
+	}
+	if _, err := h.AddChannel(DefaultGuildID, DefaultChannelID, "general", discordgo.ChannelTypeGuildText); err != nil {
+		return nil, err
+	}
+	if _, err := h.AddChannel(DefaultGuildID, DefaultVoiceChannelID, "voice", discordgo.ChannelTypeGuildVoice); err != nil {
+		return nil, err
+	}
+	if _, err := h.AddMember(DefaultGuildID, DefaultUserID, "user"); err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+// newID creates an ID for a fake discord object
+func (h *Harness) newID() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.nextID++
+	return strconv.FormatInt(h.nextID, 10)
+}
+
+// user returns the user with the ID, it is created when it does not exist
+func (h *Harness) user(id string) *discordgo.User {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if id == BotID {
+		return h.BotUser
+	}
+	u, ok := h.users[id]
+	if !ok {
+		u = &discordgo.User{ID: id, Username: "user" + id, Discriminator: "0001"}
+		h.users[id] = u
+	}
+	return u
+}
+
+// AddGuild adds a guild with an everyone role that has DefaultPermissions
+func (h *Harness) AddGuild(id, name string) (*discordgo.Guild, error) {
+	g := &discordgo.Guild{
+		ID:   id,
+		Name: name,
+		Roles: []*discordgo.Role{{
+			ID:          id,
+			Name:        "@everyone",
+			Permissions: DefaultPermissions,
+		}},
+	}
+	return g, h.Bot.Session().State.GuildAdd(g)
+}
+
+// AddChannel adds a channel to the guild
+func (h *Harness) AddChannel(guildID, id, name string, typ discordgo.ChannelType) (*discordgo.Channel, error) {
+	c := &discordgo.Channel{
+		ID:      id,
+		GuildID: guildID,
+		Name:    name,
+		Type:    typ,
+	}
+	return c, h.Bot.Session().State.ChannelAdd(c)
+}
+
+// AddRole adds a role to the guild
+func (h *Harness) AddRole(guildID, id, name string, perms int64) (*discordgo.Role, error) {
+	r := &discordgo.Role{
+		ID:          id,
+		Name:        name,
+		Permissions: perms,
+	}
+	return r, h.Bot.Session().State.RoleAdd(guildID, r)
+}
+
+// AddMember adds a user with the roles to the guild
+func (h *Harness) AddMember(guildID, userID, name string, roles ...string) (*discordgo.Member, error) {
+	u := h.user(userID)
+	u.Username = name
+	m := &discordgo.Member{
+		GuildID: guildID,
+		User:    u,
+		Roles:   roles,
+	}
+	return m, h.Bot.Session().State.MemberAdd(m)
+}
+
+// SetOwner makes the user the owner of the guild
+func (h *Harness) SetOwner(guildID, userID string) error {
+	g, err := h.Bot.Session().State.Guild(guildID)
+	if err != nil {
+		return err
+	}
+	g.OwnerID = userID
+	return nil
+}
+
+// SetVoiceState puts the user in the voice channel of the guild, an empty channel removes the user from voice
+func (h *Harness) SetVoiceState(guildID, userID, channelID string) error {
+	g, err := h.Bot.Session().State.Guild(guildID)
+	if err != nil {
+		return err
+	}
+
+	vss := g.VoiceStates[:0]
+	for _, vs := range g.VoiceStates {
+		if vs.UserID != userID {
+			vss = append(vss, vs)
+		}
+	}
+	if channelID != "" {
+		vss = append(vss, &discordgo.VoiceState{
+			UserID:    userID,
+			GuildID:   guildID,
+			ChannelID: channelID,
+		})
+	}
+	g.VoiceStates = vss
+	return nil
+}
+
+// Message creates the event of a message from the user in the channel
+func (h *Harness) Message(channelID, userID, content string) *discordgo.MessageCreate {
+	m := &discordgo.Message{
+		ID:        h.newID(),
+		ChannelID: channelID,
+		Content:   content,
+		Timestamp: time.Now(),
+		Author:    h.user(userID),
+	}
+	st := h.Bot.Session().State
+	if c, err := st.Channel(channelID); err == nil && c.GuildID != "" {
+		m.GuildID = c.GuildID
+		if mem, err := st.Member(c.GuildID, userID); err == nil {
+			m.Member = mem
+		}
+	}
+
+	h.mu.Lock()
+	h.messages[m.ID] = m
+	h.mu.Unlock()
+	return &discordgo.MessageCreate{Message: m}
+}
+
+// Send sends a message from the user in the channel and returns when the bot has handled it
+// commands that wait for replies or reactions block, use SendAsync for those
+func (h *Harness) Send(channelID, userID, content string) *discordgo.Message {
+	m := h.Message(channelID, userID, content)
+	h.Bot.Inject(m)
+	return m.Message
+}
+
+// SendAsync sends a message from the user in the channel
+// the returned channel is closed when the bot has handled it
+func (h *Harness) SendAsync(channelID, userID, content string) (*discordgo.Message, <-chan struct{}) {
+	m := h.Message(channelID, userID, content)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.Bot.Inject(m)
+	}()
+	return m.Message, done
+}
+
+// SendIdle sends a message from the user in the channel and returns once the bot is idle:
+// when the commands it started are done or wait for a reply or reaction
+// unlike SendAsync the next message can be sent right away, e.g. the answer to the question of a dialog
+func (h *Harness) SendIdle(channelID, userID, content string) (*discordgo.Message, error) {
+	m := h.Message(channelID, userID, content)
+	return m.Message, h.injectIdle(m)
+}
+
+// injectIdle injects the event and waits until the bot is idle
+func (h *Harness) injectIdle(e interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), IdleTimeout)
+	defer cancel()
+
+	if err := h.Bot.InjectIdle(ctx, e); err != nil {
+		return fmt.Errorf("%v after %v", ErrNotIdle, IdleTimeout)
+	}
+	return nil
+}
+
+// Say sends a message from the default user in the default channel
+func (h *Harness) Say(content string) *discordgo.Message {
+	return h.Send(DefaultChannelID, DefaultUserID, content)
+}
+
+// React adds a reaction from the user to the message
+func (h *Harness) React(channelID, messageID, userID, emoji string) {
+	h.Bot.Inject(h.reaction(channelID, messageID, userID, emoji))
+}
+
+// ReactIdle adds a reaction from the user to the message and returns once the bot is idle like SendIdle
+func (h *Harness) ReactIdle(channelID, messageID, userID, emoji string) error {
+	return h.injectIdle(h.reaction(channelID, messageID, userID, emoji))
+}
+
+// reaction creates the event of a reaction from the user
+func (h *Harness) reaction(channelID, messageID, userID, emoji string) *discordgo.MessageReactionAdd {
+	r := &discordgo.MessageReaction{
+		UserID:    userID,
+		MessageID: messageID,
+		ChannelID: channelID,
+		Emoji:     discordgo.Emoji{Name: emoji},
+	}
+	if c, err := h.Bot.Session().State.Channel(channelID); err == nil {
+		r.GuildID = c.GuildID
+	}
+	return &discordgo.MessageReactionAdd{MessageReaction: r}
+}
+
+// record adds an action done by the bot
+func (h *Harness) record(a Action) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if a.Time.IsZero() {
+		a.Time = time.Now()
+	}
+	h.actions = append(h.actions, a)
+}
+
+// Actions returns everything the bot did in order
+func (h *Harness) Actions() []Action {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return append([]Action(nil), h.actions...)
+}
+
+// ActionsOf returns the actions of the type in order
+func (h *Harness) ActionsOf(t ActionType) []Action {
+	var as []Action
+	for _, a := range h.Actions() {
+		if a.Type == t {
+			as = append(as, a)
+		}
+	}
+	return as
+}
+
+// Messages returns the messages sent by the bot as they were sent
+func (h *Harness) Messages() []*discordgo.Message {
+	var ms []*discordgo.Message
+	for _, a := range h.ActionsOf(ActionSend) {
+		ms = append(ms, a.Message)
+	}
+	return ms
+}
+
+// LastMessage returns the last message sent by the bot or nil
+func (h *Harness) LastMessage() *discordgo.Message {
+	ms := h.Messages()
+	if len(ms) == 0 {
+		return nil
+	}
+	return ms[len(ms)-1]
+}
+
+// CurrentMessage returns the message with the ID with all edits applied or nil when it does not exist
+func (h *Harness) CurrentMessage(id string) *discordgo.Message {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	m, ok := h.messages[id]
+	if !ok {
+		return nil
+	}
+	return copyMessage(m)
+}
+
+// Reset forgets all recorded actions
+func (h *Harness) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.actions = nil
+}
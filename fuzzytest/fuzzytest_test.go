@@ -0,0 +1,147 @@
+package fuzzytest_test
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/fvdveen/fuzzy"
+	"github.com/fvdveen/fuzzy/fuzzytest"
+)
+
+type frames struct {
+	frames [][]byte
+	i      int
+}
+
+func (f *frames) OpusFrame() ([]byte, error) {
+	if f.i >= len(f.frames) {
+		return nil, io.EOF
+	}
+	f.i++
+	return f.frames[f.i-1], nil
+}
+
+func (f *frames) ResetPlayback() {
+	f.i = 0
+}
+
+func TestHarness(t *testing.T) {
+	h, err := fuzzytest.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := h.Bot.RegisterCommand(
+		fuzzy.NewCommand("ping", "sends pong", func(ctx fuzzy.Context) {
+			m, _ := ctx.SendMessage("pong")
+			_, _ = ctx.Edit(m, "pong!")
+			_ = ctx.React("🏓")
+		}),
+		fuzzy.NewCommand("admin", "only for admins", func(ctx fuzzy.Context) {
+			_, _ = ctx.DM("welcome admin")
+		}, fuzzy.WithPermissions(discordgo.PermissionManageServer)),
+		fuzzy.NewCommand("play", "plays frames", func(ctx fuzzy.Context) {
+			if err := ctx.PlaySound(&frames{frames: [][]byte{{1}, {2}, {3}}}); err != nil {
+				_, _ = ctx.SendError(err)
+			}
+		}),
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	h.Say("!ping")
+	m := h.LastMessage()
+	if m == nil || m.Content != "pong" {
+		t.Fatalf("expected pong got %v", m)
+	}
+	if cur := h.CurrentMessage(m.ID); cur.Content != "pong!" {
+		t.Fatalf("expected the message to be edited to pong! got %s", cur.Content)
+	}
+	if rs := h.ActionsOf(fuzzytest.ActionReact); len(rs) != 1 || rs[0].Emoji != "🏓" {
+		t.Fatalf("expected a 🏓 reaction got %v", rs)
+	}
+
+	h.Reset()
+	h.Say("!admin")
+	m = h.LastMessage()
+	if m == nil || len(m.Embeds) != 1 || !strings.Contains(m.Embeds[0].Description, "permissions") {
+		t.Fatalf("expected a permissions error got %v", m)
+	}
+
+	if _, err := h.AddRole(fuzzytest.DefaultGuildID, "5000", "admin", discordgo.PermissionManageServer); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.AddMember(fuzzytest.DefaultGuildID, fuzzytest.DefaultUserID, "user", "5000"); err != nil {
+		t.Fatal(err)
+	}
+	h.Reset()
+	h.Say("!admin")
+	m = h.LastMessage()
+	if m == nil || m.Content != "welcome admin" || m.ChannelID != "dm-"+fuzzytest.DefaultUserID {
+		t.Fatalf("expected a dm got %v", m)
+	}
+
+	h.Reset()
+	h.Say("!play")
+	if m := h.LastMessage(); m == nil || len(m.Embeds) != 1 {
+		t.Fatalf("expected an error without a voice state got %v", m)
+	}
+
+	if err := h.SetVoiceState(fuzzytest.DefaultGuildID, fuzzytest.DefaultUserID, fuzzytest.DefaultVoiceChannelID); err != nil {
+		t.Fatal(err)
+	}
+	h.Reset()
+	h.Say("!play")
+	as := h.Actions()
+	if len(as) != 2 || as[0].Type != fuzzytest.ActionVoiceJoin || as[0].ChannelID != fuzzytest.DefaultVoiceChannelID {
+		t.Fatalf("expected the bot to join the voice channel got %v", as)
+	}
+	if as[1].Type != fuzzytest.ActionVoicePlay || len(as[1].Frames) != 3 {
+		t.Fatalf("expected 3 frames to be played got %v", as[1])
+	}
+}
+
+func TestHarnessEmbeds(t *testing.T) {
+	h, err := fuzzytest.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Bot.RegisterCommand(fuzzy.NewCommand("cards", "", func(ctx fuzzy.Context) {
+		m, err := ctx.SendComplex(&discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{{Title: "One"}, {Title: "Two"}}})
+		if err != nil {
+			return
+		}
+		e := discordgo.NewMessageEdit(m.ChannelID, m.ID).SetEmbeds([]*discordgo.MessageEmbed{{Title: "Three"}})
+		_, _ = ctx.Transport().ChannelMessageEditComplex(e)
+	})); err != nil {
+		t.Fatal(err)
+	}
+
+	h.Say("!cards")
+	m := h.LastMessage()
+	if m == nil || len(m.Embeds) != 2 || m.Embeds[0].Title != "One" || m.Embeds[1].Title != "Two" {
+		t.Fatalf("expected both embeds to be sent got %v", m)
+	}
+	if cur := h.CurrentMessage(m.ID); len(cur.Embeds) != 1 || cur.Embeds[0].Title != "Three" || cur.EditedTimestamp == nil {
+		t.Fatalf("expected the embeds to be edited got %v", cur)
+	}
+}
+
+func TestHarnessGenerator(t *testing.T) {
+	g := fuzzy.DefaultGenerator()
+	if _, err := fuzzytest.New(fuzzy.WithGenerator(g)); err != nil {
+		t.Fatal(err)
+	}
+
+	// the harness does not change the generator of the caller
+	b, err := fuzzy.New(fuzzy.WithConfig(&fuzzy.Config{Token: "token", Prefix: "!"}), fuzzy.WithGenerator(g))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tr := fmt.Sprintf("%T", b.Transport()); tr != "fuzzy.discordgoTransport" {
+		t.Fatalf("expected the default transport got %s", tr)
+	}
+}
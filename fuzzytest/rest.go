@@ -0,0 +1,307 @@
+package fuzzytest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// apiPath matches the part of a discord API URL after the version
+var apiPath = regexp.MustCompile(`/api/v\d+/(.*)$`)
+
+// route is a REST endpoint of the fake discord API
+type route struct {
+	method  string
+	pattern *regexp.Regexp
+	handle  func(h *Harness, r *http.Request, args []string) (interface{}, int)
+}
+
+var routes = []route{
+	{"POST", regexp.MustCompile(`^channels/([^/]+)/messages$`), (*Harness).createMessage},
+	{"PATCH", regexp.MustCompile(`^channels/([^/]+)/messages/([^/]+)$`), (*Harness).editMessage},
+	{"DELETE", regexp.MustCompile(`^channels/([^/]+)/messages/([^/]+)$`), (*Harness).deleteMessage},
+	{"GET", regexp.MustCompile(`^channels/([^/]+)/messages/([^/]+)$`), (*Harness).getMessage},
+	{"PUT", regexp.MustCompile(`^channels/([^/]+)/messages/([^/]+)/reactions/([^/]+)/@me$`), (*Harness).addReaction},
+	{"DELETE", regexp.MustCompile(`^channels/([^/]+)/messages/([^/]+)/reactions/([^/]+)/([^/]+)$`), (*Harness).removeReaction},
+	{"DELETE", regexp.MustCompile(`^channels/([^/]+)/messages/([^/]+)/reactions$`), (*Harness).removeAllReactions},
+	{"POST", regexp.MustCompile(`^channels/([^/]+)/typing$`), (*Harness).typing},
+	{"GET", regexp.MustCompile(`^channels/([^/]+)$`), (*Harness).getChannel},
+	{"POST", regexp.MustCompile(`^users/@me/channels$`), (*Harness).createDM},
+	{"GET", regexp.MustCompile(`^guilds/([^/]+)$`), (*Harness).getGuild},
+	{"GET", regexp.MustCompile(`^guilds/([^/]+)/members/([^/]+)$`), (*Harness).getMember},
+	{"GET", regexp.MustCompile(`^guilds/([^/]+)/roles$`), (*Harness).getRoles},
+}
+
+// apiError is the body of a failed request
+type apiError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// RoundTrip implements http.RoundTripper by answering requests to the discord API from the fake state
+func (h *Harness) RoundTrip(r *http.Request) (*http.Response, error) {
+	var (
+		v      interface{} = apiError{Message: "404: Not Found"}
+		status             = http.StatusNotFound
+	)
+
+	if m := apiPath.FindStringSubmatch(r.URL.EscapedPath()); m != nil {
+		for _, rt := range routes {
+			if rt.method != r.Method {
+				continue
+			}
+			args := rt.pattern.FindStringSubmatch(m[1])
+			if args == nil {
+				continue
+			}
+			for i := range args {
+				args[i], _ = url.PathUnescape(args[i])
+			}
+			v, status = rt.handle(h, r, args[1:])
+			break
+		}
+	}
+
+	resp := &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Request:    r,
+		Body:       ioutil.NopCloser(&bytes.Buffer{}),
+	}
+	if v != nil {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = ioutil.NopCloser(bytes.NewReader(b))
+		resp.ContentLength = int64(len(b))
+	}
+	return resp, nil
+}
+
+// decodeBody decodes a JSON or multipart body into v and returns the uploaded files
+func decodeBody(r *http.Request, v interface{}) ([]File, error) {
+	if r.Body == nil {
+		return nil, nil
+	}
+	defer r.Body.Close()
+
+	mt, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if !strings.HasPrefix(mt, "multipart/") {
+		return nil, json.NewDecoder(r.Body).Decode(v)
+	}
+
+	var files []File
+	mr := multipart.NewReader(r.Body, params["boundary"])
+	for {
+		p, err := mr.NextPart()
+		if err != nil {
+			break
+		}
+		b, err := ioutil.ReadAll(p)
+		if err != nil {
+			return nil, err
+		}
+		if p.FormName() == "payload_json" {
+			if err := json.Unmarshal(b, v); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		files = append(files, File{Name: p.FileName(), Data: b})
+	}
+	return files, nil
+}
+
+func notFound() (interface{}, int) {
+	return apiError{Message: "404: Not Found"}, http.StatusNotFound
+}
+
+func badRequest(err error) (interface{}, int) {
+	return apiError{Message: err.Error()}, http.StatusBadRequest
+}
+
+func (h *Harness) createMessage(r *http.Request, args []string) (interface{}, int) {
+	var data discordgo.MessageSend
+	files, err := decodeBody(r, &data)
+	if err != nil {
+		return badRequest(err)
+	}
+
+	m := &discordgo.Message{
+		ID:               h.newID(),
+		ChannelID:        args[0],
+		Content:          data.Content,
+		Timestamp:        time.Now(),
+		Author:           h.BotUser,
+		MessageReference: data.Reference,
+	}
+	if c, err := h.Bot.Session().State.Channel(args[0]); err == nil {
+		m.GuildID = c.GuildID
+	}
+	// discordgo sends the embeds of a message as embeds, even the single Embed
+	m.Embeds = data.Embeds
+	for _, f := range files {
+		m.Attachments = append(m.Attachments, &discordgo.MessageAttachment{
+			ID:       h.newID(),
+			Filename: f.Name,
+			Size:     len(f.Data),
+		})
+	}
+
+	h.mu.Lock()
+	h.messages[m.ID] = m
+	h.mu.Unlock()
+	h.record(Action{Type: ActionSend, ChannelID: m.ChannelID, MessageID: m.ID, Message: copyMessage(m), Files: files})
+	return m, http.StatusOK
+}
+
+func (h *Harness) editMessage(r *http.Request, args []string) (interface{}, int) {
+	// the body is not decoded into discordgo.MessageEdit, the type of its Embeds differs between versions
+	var data struct {
+		Content *string                    `json:"content"`
+		Embeds  *[]*discordgo.MessageEmbed `json:"embeds"`
+	}
+	if _, err := decodeBody(r, &data); err != nil {
+		return badRequest(err)
+	}
+
+	h.mu.Lock()
+	m, ok := h.messages[args[1]]
+	if ok {
+		if data.Content != nil {
+			m.Content = *data.Content
+		}
+		if data.Embeds != nil {
+			m.Embeds = *data.Embeds
+		}
+		now := time.Now()
+		m.EditedTimestamp = &now
+		m = copyMessage(m)
+	}
+	h.mu.Unlock()
+	if !ok {
+		return notFound()
+	}
+
+	h.record(Action{Type: ActionEdit, ChannelID: args[0], MessageID: args[1], Message: m})
+	return m, http.StatusOK
+}
+
+func (h *Harness) deleteMessage(r *http.Request, args []string) (interface{}, int) {
+	h.mu.Lock()
+	_, ok := h.messages[args[1]]
+	delete(h.messages, args[1])
+	h.mu.Unlock()
+	if !ok {
+		return notFound()
+	}
+
+	h.record(Action{Type: ActionDelete, ChannelID: args[0], MessageID: args[1]})
+	return nil, http.StatusNoContent
+}
+
+func (h *Harness) getMessage(r *http.Request, args []string) (interface{}, int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	m, ok := h.messages[args[1]]
+	if !ok {
+		return notFound()
+	}
+	return m, http.StatusOK
+}
+
+func (h *Harness) addReaction(r *http.Request, args []string) (interface{}, int) {
+	h.record(Action{Type: ActionReact, ChannelID: args[0], MessageID: args[1], Emoji: args[2], UserID: h.BotUser.ID})
+	return nil, http.StatusNoContent
+}
+
+func (h *Harness) removeReaction(r *http.Request, args []string) (interface{}, int) {
+	h.record(Action{Type: ActionUnreact, ChannelID: args[0], MessageID: args[1], Emoji: args[2], UserID: args[3]})
+	return nil, http.StatusNoContent
+}
+
+func (h *Harness) removeAllReactions(r *http.Request, args []string) (interface{}, int) {
+	h.record(Action{Type: ActionClearReactions, ChannelID: args[0], MessageID: args[1]})
+	return nil, http.StatusNoContent
+}
+
+func (h *Harness) typing(r *http.Request, args []string) (interface{}, int) {
+	h.record(Action{Type: ActionTyping, ChannelID: args[0]})
+	return nil, http.StatusNoContent
+}
+
+func (h *Harness) getChannel(r *http.Request, args []string) (interface{}, int) {
+	c, err := h.Bot.Session().State.Channel(args[0])
+	if err != nil {
+		return notFound()
+	}
+	return c, http.StatusOK
+}
+
+func (h *Harness) createDM(r *http.Request, args []string) (interface{}, int) {
+	var data struct {
+		RecipientID string `json:"recipient_id"`
+	}
+	if _, err := decodeBody(r, &data); err != nil {
+		return badRequest(err)
+	}
+
+	id := "dm-" + data.RecipientID
+	st := h.Bot.Session().State
+	c, err := st.Channel(id)
+	if err != nil {
+		c = &discordgo.Channel{
+			ID:         id,
+			Type:       discordgo.ChannelTypeDM,
+			Recipients: []*discordgo.User{h.user(data.RecipientID)},
+		}
+		if err := st.ChannelAdd(c); err != nil {
+			return badRequest(err)
+		}
+	}
+	return c, http.StatusOK
+}
+
+func (h *Harness) getGuild(r *http.Request, args []string) (interface{}, int) {
+	g, err := h.Bot.Session().State.Guild(args[0])
+	if err != nil {
+		return notFound()
+	}
+	return g, http.StatusOK
+}
+
+func (h *Harness) getMember(r *http.Request, args []string) (interface{}, int) {
+	m, err := h.Bot.Session().State.Member(args[0], args[1])
+	if err != nil {
+		return notFound()
+	}
+	return m, http.StatusOK
+}
+
+func (h *Harness) getRoles(r *http.Request, args []string) (interface{}, int) {
+	g, err := h.Bot.Session().State.Guild(args[0])
+	if err != nil {
+		return notFound()
+	}
+	return g.Roles, http.StatusOK
+}
+
+// copyMessage copies the message so later edits do not change recorded actions
+func copyMessage(m *discordgo.Message) *discordgo.Message {
+	m2 := *m
+	m2.Embeds = append([]*discordgo.MessageEmbed(nil), m.Embeds...)
+	m2.Attachments = append([]*discordgo.MessageAttachment(nil), m.Attachments...)
+	return &m2
+}
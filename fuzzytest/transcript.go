@@ -0,0 +1,248 @@
+package fuzzytest
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// update makes Transcript rewrite the transcripts with what the bot did
+var update = flag.Bool("fuzzytest.update", false, "rewrite the fuzzytest transcripts with the responses of the bot")
+
+// step is a line sent by the user and the lines the bot is expected to respond with
+type step struct {
+	// comments are the comment and blank lines above the step
+	comments []string
+	input    string
+	expected []string
+}
+
+// transcript is a parsed transcript file
+type transcript struct {
+	steps []step
+	// trailing are the comment and blank lines below the last step
+	trailing []string
+}
+
+// Transcript replays the transcript file against the bot and fails the test when it responds differently
+//
+// lines starting with "> " are messages from the default user in the default channel and
+// lines starting with "+ " are reactions of the default user to the last message of the bot,
+// the lines starting with "<" below them are what the bot does in response:
+//
+//	# a comment
+//	> !ping
+//	< pong
+//	< react 🏓
+//	> !card
+//	< embed: Card
+//	<   some text
+//	<   field: value
+//
+// embeds, edits, deletes, reactions, files and voice are written like "go test -fuzzytest.update" writes them,
+// typing is left out, a "*" matches any text within a line and a "..." line matches any amount of lines
+//
+// each line is sent once the bot is idle like SendIdle, so the answers of a dialog reach the dialog
+//
+// with -fuzzytest.update the transcript is rewritten with the responses of the bot, wildcards are lost
+func (h *Harness) Transcript(t testing.TB, path string) {
+	t.Helper()
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("could not read transcript: %v", err)
+	}
+	tr, err := parseTranscript(b)
+	if err != nil {
+		t.Fatalf("could not parse transcript %s: %v", path, err)
+	}
+
+	for i, s := range tr.steps {
+		actual, err := h.runStep(s.input)
+		if err != nil {
+			t.Fatalf("%s: %q: %v", path, s.input, err)
+		}
+		if *update {
+			tr.steps[i].expected = actual
+			continue
+		}
+		if !matchLines(s.expected, actual) {
+			t.Errorf("%s: unexpected response to %q\nexpected:\n%s\ngot:\n%s", path, s.input,
+				strings.Join(s.expected, "\n"), strings.Join(actual, "\n"))
+		}
+	}
+
+	if *update {
+		if err := ioutil.WriteFile(path, tr.format(), 0644); err != nil {
+			t.Fatalf("could not update transcript: %v", err)
+		}
+	}
+}
+
+// parseTranscript splits a transcript into steps
+func parseTranscript(b []byte) (*transcript, error) {
+	var (
+		steps    []step
+		comments []string
+	)
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	for n := 1; sc.Scan(); n++ {
+		l := sc.Text()
+		switch {
+		case strings.TrimSpace(l) == "" || strings.HasPrefix(l, "#"):
+			comments = append(comments, l)
+		case strings.HasPrefix(l, "> ") || strings.HasPrefix(l, "+ "):
+			steps = append(steps, step{comments: comments, input: l})
+			comments = nil
+		case strings.HasPrefix(l, "<"):
+			if len(steps) == 0 {
+				return nil, fmt.Errorf("line %d: response before the first message", n)
+			}
+			s := &steps[len(steps)-1]
+			s.expected = append(s.expected, strings.TrimPrefix(strings.TrimPrefix(l, "<"), " "))
+		default:
+			return nil, fmt.Errorf("line %d: unknown line %q", n, l)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return &transcript{steps: steps, trailing: comments}, nil
+}
+
+// format writes the transcript in its file format
+func (tr *transcript) format() []byte {
+	var buf bytes.Buffer
+	for _, s := range tr.steps {
+		for _, c := range s.comments {
+			fmt.Fprintln(&buf, c)
+		}
+		fmt.Fprintln(&buf, s.input)
+		for _, l := range s.expected {
+			fmt.Fprintln(&buf, "< "+l)
+		}
+	}
+	for _, c := range tr.trailing {
+		fmt.Fprintln(&buf, c)
+	}
+	return buf.Bytes()
+}
+
+// runStep sends the input line and returns the formatted actions of the bot until it is idle
+func (h *Harness) runStep(input string) ([]string, error) {
+	start := len(h.Actions())
+
+	var err error
+	if strings.HasPrefix(input, "+ ") {
+		var mid string
+		if m := h.LastMessage(); m != nil {
+			mid = m.ID
+		}
+		err = h.ReactIdle(DefaultChannelID, mid, DefaultUserID, strings.TrimSpace(input[2:]))
+	} else {
+		_, err = h.SendIdle(DefaultChannelID, DefaultUserID, strings.TrimSpace(input[2:]))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	for _, a := range h.Actions()[start:] {
+		lines = append(lines, formatAction(a)...)
+	}
+	return lines, nil
+}
+
+// formatAction writes an action as transcript lines
+func formatAction(a Action) []string {
+	switch a.Type {
+	case ActionSend:
+		return formatMessage("", a.Message, a.Files)
+	case ActionEdit:
+		return formatMessage("edit ", a.Message, nil)
+	case ActionDelete:
+		return []string{"delete"}
+	case ActionReact:
+		return []string{"react " + a.Emoji}
+	case ActionUnreact:
+		return []string{"unreact " + a.Emoji}
+	case ActionClearReactions:
+		return []string{"clear reactions"}
+	case ActionTyping:
+		return nil
+	case ActionVoicePlay:
+		return []string{fmt.Sprintf("voice play %d frames", len(a.Frames))}
+	default:
+		return []string{strings.Replace(string(a.Type), "_", " ", -1)}
+	}
+}
+
+// formatMessage writes the content, embeds and files of a message as transcript lines
+func formatMessage(prefix string, m *discordgo.Message, files []File) []string {
+	var lines []string
+	if m.Content != "" {
+		for _, l := range strings.Split(m.Content, "\n") {
+			lines = append(lines, prefix+l)
+		}
+	}
+	for _, e := range m.Embeds {
+		lines = append(lines, prefix+"embed: "+e.Title)
+		for _, l := range strings.Split(e.Description, "\n") {
+			if l != "" {
+				lines = append(lines, "  "+l)
+			}
+		}
+		for _, f := range e.Fields {
+			lines = append(lines, "  "+f.Name+": "+strings.Replace(f.Value, "\n", " ", -1))
+		}
+		if e.Footer != nil && e.Footer.Text != "" {
+			lines = append(lines, "  -- "+e.Footer.Text)
+		}
+	}
+	for _, f := range files {
+		lines = append(lines, fmt.Sprintf("%sfile: %s (%d bytes)", prefix, f.Name, len(f.Data)))
+	}
+	return lines
+}
+
+// matchLines reports whether the actual lines match the expected lines with wildcards
+func matchLines(expected, actual []string) bool {
+	if len(expected) == 0 {
+		return len(actual) == 0
+	}
+	if expected[0] == "..." {
+		for i := 0; i <= len(actual); i++ {
+			if matchLines(expected[1:], actual[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	return len(actual) > 0 && matchLine(expected[0], actual[0]) && matchLines(expected[1:], actual[1:])
+}
+
+// matchLine reports whether the line matches the pattern where "*" matches any text
+func matchLine(pattern, line string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == line
+	}
+	if !strings.HasPrefix(line, parts[0]) {
+		return false
+	}
+	line = line[len(parts[0]):]
+	for _, p := range parts[1 : len(parts)-1] {
+		i := strings.Index(line, p)
+		if i < 0 {
+			return false
+		}
+		line = line[i+len(p):]
+	}
+	return strings.HasSuffix(line, parts[len(parts)-1])
+}
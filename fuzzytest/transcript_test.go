@@ -0,0 +1,160 @@
+package fuzzytest_test
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/fvdveen/fuzzy"
+	"github.com/fvdveen/fuzzy/fuzzytest"
+)
+
+// failures records the failures of a test instead of failing it
+type failures struct {
+	testing.TB
+	errors []string
+}
+
+func (f *failures) Helper() {}
+
+func (f *failures) Errorf(format string, args ...interface{}) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+// Fatalf stops the goroutine like testing.T does, the transcript must be run by run
+func (f *failures) Fatalf(format string, args ...interface{}) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+	runtime.Goexit()
+}
+
+// run replays the transcript in its own goroutine so Fatalf can stop it
+func (f *failures) run(h *fuzzytest.Harness, path string) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.Transcript(f, path)
+	}()
+	<-done
+}
+
+func newTranscriptHarness(t *testing.T) *fuzzytest.Harness {
+	h, err := fuzzytest.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := h.Bot.RegisterCommand(
+		fuzzy.NewCommand("signup", "asks your name and age", func(ctx fuzzy.Context) {
+			var ans struct {
+				Name string
+				Age  int
+			}
+			d := fuzzy.NewDialog(
+				&fuzzy.Step{Name: "Name", Prompt: "What is your name?"},
+				&fuzzy.Step{Name: "Age", Prompt: "How old are you?"},
+			)
+			if err := d.Run(ctx, &ans); err != nil {
+				_, _ = ctx.SendError(err)
+				return
+			}
+			_, _ = ctx.SendMessage(fmt.Sprintf("Welcome %s (%d)", ans.Name, ans.Age))
+			_ = ctx.React("✅")
+		}),
+		fuzzy.NewCommand("slow", "asks a question after a while", func(ctx fuzzy.Context) {
+			time.Sleep(150 * time.Millisecond)
+			_, _ = ctx.SendMessage("ready?")
+			if _, err := ctx.WaitForReply(time.Minute); err != nil {
+				return
+			}
+			time.Sleep(150 * time.Millisecond)
+			_, _ = ctx.SendMessage("go")
+		}),
+		fuzzy.NewCommand("card", "sends an embed", func(ctx fuzzy.Context) {
+			_, _ = ctx.SendEmbed(fuzzy.NewEmbed().SetTitle("Card").SetDescription("some text").AddField("field", "value", false).Build())
+		}),
+	); err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func TestTranscript(t *testing.T) {
+	newTranscriptHarness(t).Transcript(t, filepath.Join("testdata", "signup.txt"))
+}
+
+func TestTranscriptMismatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		transcript string
+		failures   int
+	}{
+		{"match", "> !card\n< embed: Card\n< ...\n", 0},
+		{"wildcard", "> !card\n< embed: C*d\n<   * text\n<   field: *\n", 0},
+		{"wrong content", "> !card\n< embed: Cart\n< ...\n", 1},
+		{"missing line", "> !card\n< embed: Card\n<   some text\n", 1},
+		{"unexpected line", "> !card\n< embed: Card\n< ...\n< pong\n", 1},
+		{"no response", "> hello\n< hi\n", 1},
+		{"slow handler", "> !slow\n< ready?\n> yes\n< go\n", 0},
+		{"bad line", "hello\n", 1},
+	}
+
+	dir, err := ioutil.TempDir("", "fuzzytest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, fmt.Sprintf("%d.txt", i))
+			if err := ioutil.WriteFile(path, []byte(tt.transcript), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			f := &failures{TB: t}
+			f.run(newTranscriptHarness(t), path)
+			if len(f.errors) != tt.failures {
+				t.Fatalf("expected %d failures got %v", tt.failures, f.errors)
+			}
+		})
+	}
+}
+
+func TestTranscriptUpdate(t *testing.T) {
+	f, err := ioutil.TempFile("", "fuzzytest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = os.Remove(f.Name())
+	}()
+	if _, err := f.WriteString("# card\n> !card\n< wrong\n\n# the end\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := flag.Set("fuzzytest.update", "true"); err != nil {
+		t.Fatal(err)
+	}
+	newTranscriptHarness(t).Transcript(t, f.Name())
+	if err := flag.Set("fuzzytest.update", "false"); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := "# card\n> !card\n< embed: Card\n<   some text\n<   field: value\n\n# the end\n"; string(b) != exp {
+		t.Fatalf("expected transcript\n%s\ngot\n%s", exp, b)
+	}
+}
@@ -0,0 +1,117 @@
+package fuzzytest
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/fvdveen/fuzzy"
+)
+
+// voiceHandler is a fuzzy.VoiceHandler which reads the frames of all items it is given
+// right away and records them in the harness instead of sending them to discord
+type voiceHandler struct {
+	h   *Harness
+	bot *fuzzy.Bot
+	gid string
+
+	mu sync.Mutex
+}
+
+// voiceHandlerGenerator creates recording voice handlers for the harness
+func (h *Harness) voiceHandlerGenerator(_ fuzzy.Transport, b *fuzzy.Bot, gid, voiceChanID, textChanID string, vi fuzzy.VoiceItem) fuzzy.VoiceHandler {
+	vh := &voiceHandler{
+		h:   h,
+		bot: b,
+		gid: gid,
+	}
+	h.record(Action{Type: ActionVoiceJoin, GuildID: gid, ChannelID: voiceChanID})
+	vh.Play(vi)
+	return vh
+}
+
+func (vh *voiceHandler) Play(vi fuzzy.VoiceItem) {
+	vh.mu.Lock()
+	defer vh.mu.Unlock()
+
+	vh.bot.Events().Publish(&fuzzy.VoiceTrackStarted{GuildID: vh.gid, Item: vi})
+	var (
+		frames [][]byte
+		err    error
+	)
+	for {
+		var f []byte
+		f, err = vi.OpusFrame()
+		if err != nil {
+			break
+		}
+		frames = append(frames, f)
+	}
+	if err == io.EOF {
+		err = nil
+	}
+	vh.h.record(Action{Type: ActionVoicePlay, GuildID: vh.gid, Frames: frames})
+	vh.bot.Events().Publish(&fuzzy.VoiceTrackEnded{GuildID: vh.gid, Item: vi, Err: err})
+}
+
+func (vh *voiceHandler) Skip() {
+	vh.h.record(Action{Type: ActionVoiceSkip, GuildID: vh.gid})
+}
+
+func (vh *voiceHandler) Stop() {
+	vh.h.record(Action{Type: ActionVoiceStop, GuildID: vh.gid})
+	vh.bot.DeleteVoiceHandler(vh.gid)
+}
+
+func (vh *voiceHandler) Pause() {
+	vh.h.record(Action{Type: ActionVoicePause, GuildID: vh.gid})
+}
+
+func (vh *voiceHandler) Resume() {
+	vh.h.record(Action{Type: ActionVoiceResume, GuildID: vh.gid})
+}
+
+func (vh *voiceHandler) Loop() {
+	vh.h.record(Action{Type: ActionVoiceLoop, GuildID: vh.gid})
+}
+
+func (vh *voiceHandler) Repeat() {
+	vh.h.record(Action{Type: ActionVoiceRepeat, GuildID: vh.gid})
+}
+
+// Queue is always empty as items are played right away
+func (vh *voiceHandler) Queue() []fuzzy.VoiceItem {
+	return nil
+}
+
+func (vh *voiceHandler) NowPlaying() fuzzy.VoiceItem {
+	return nil
+}
+
+func (vh *voiceHandler) Position() time.Duration {
+	return 0
+}
+
+func (vh *voiceHandler) Remove(i int) error {
+	return fuzzy.ErrQueueIndex
+}
+
+func (vh *voiceHandler) Move(a, b int) error {
+	return fuzzy.ErrQueueIndex
+}
+
+func (vh *voiceHandler) Insert(i int, vi fuzzy.VoiceItem) error {
+	if i != 0 {
+		return fuzzy.ErrQueueIndex
+	}
+	vh.Play(vi)
+	return nil
+}
+
+func (vh *voiceHandler) PlayNext(vi fuzzy.VoiceItem) {
+	vh.Play(vi)
+}
+
+func (vh *voiceHandler) Clear() {}
+
+func (vh *voiceHandler) Shuffle() {}
@@ -0,0 +1,244 @@
+package fuzzytest
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/fvdveen/fuzzy"
+)
+
+// FrameDuration is the duration of a single opus frame sent to discord
+const FrameDuration = fuzzy.FrameDuration
+
+var (
+	// ErrVoiceDisconnected is used when a fake voice connection has been dropped or disconnected
+	ErrVoiceDisconnected = errors.New("voice connection is disconnected")
+	// ErrNoFrame is used when the bot does not send a frame in time
+	ErrNoFrame = errors.New("no frame was sent")
+	// ErrNoVoiceConnection is used when the bot does not join a voice channel in time
+	ErrNoVoiceConnection = errors.New("no voice connection was opened")
+)
+
+// Clock is a deterministic clock which only moves when it is advanced
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewClock creates a clock set to the time
+func NewClock(t time.Time) *Clock {
+	return &Clock{now: t}
+}
+
+// Now returns the time of the clock
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// Advance moves the clock forward
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+// Frame is an opus frame received by a fake voice connection
+type Frame struct {
+	Data []byte
+	// Time is the time of the clock when the frame was received
+	Time time.Time
+}
+
+// VoiceConnection is a fake fuzzy.VoiceConnection which only takes frames when they are received by the test
+// this lets tests control exactly how far playback gets, e.g. to pause or skip after the third frame
+type VoiceConnection struct {
+	GuildID   string
+	ChannelID string
+
+	h    *Harness
+	send chan []byte
+	done chan struct{}
+
+	mu           sync.Mutex
+	frames       []Frame
+	speaking     bool
+	dropped      bool
+	disconnected bool
+}
+
+// Speaking sets the speaking state of the bot
+func (vc *VoiceConnection) Speaking(b bool) error {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
+	if vc.dropped || vc.disconnected {
+		return ErrVoiceDisconnected
+	}
+	vc.speaking = b
+	return nil
+}
+
+// OpusSend returns the channel the bot sends frames on
+func (vc *VoiceConnection) OpusSend() chan<- []byte {
+	return vc.send
+}
+
+// Disconnect disconnects the bot from the voice channel
+func (vc *VoiceConnection) Disconnect() error {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
+	if vc.disconnected {
+		return ErrVoiceDisconnected
+	}
+	vc.disconnected = true
+	vc.speaking = false
+	close(vc.done)
+	vc.h.record(Action{Type: ActionVoiceLeave, GuildID: vc.GuildID, ChannelID: vc.ChannelID})
+	return nil
+}
+
+// Receive takes n frames sent by the bot and advances the clock of the harness by FrameDuration for each
+// it returns the frames received before the bot stopped sending for the timeout or disconnected
+func (vc *VoiceConnection) Receive(n int, timeout time.Duration) ([]Frame, error) {
+	var fs []Frame
+	for i := 0; i < n; i++ {
+		vc.mu.Lock()
+		dropped := vc.dropped
+		vc.mu.Unlock()
+		if dropped {
+			return fs, ErrVoiceDisconnected
+		}
+
+		select {
+		case d := <-vc.send:
+			f := Frame{Data: d, Time: vc.h.Clock.Now()}
+			vc.h.Clock.Advance(FrameDuration)
+
+			vc.mu.Lock()
+			vc.frames = append(vc.frames, f)
+			vc.mu.Unlock()
+			fs = append(fs, f)
+		case <-vc.done:
+			return fs, ErrVoiceDisconnected
+		case <-time.After(timeout):
+			return fs, fmt.Errorf("%v: got %d of %d frames", ErrNoFrame, len(fs), n)
+		}
+	}
+	return fs, nil
+}
+
+// Drop simulates a lost connection, no frames are received from then on
+// like a lost discordgo connection it only freezes the connection: the bot is not told about the drop,
+// Speaking fails but sending frames blocks until the bot stops playing or disconnects
+func (vc *VoiceConnection) Drop() {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
+	vc.dropped = true
+	vc.speaking = false
+}
+
+// Frames returns all frames received in order
+func (vc *VoiceConnection) Frames() []Frame {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
+	return append([]Frame(nil), vc.frames...)
+}
+
+// IsSpeaking reports whether the bot is speaking
+func (vc *VoiceConnection) IsSpeaking() bool {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
+	return vc.speaking
+}
+
+// WaitDisconnect waits until the bot disconnects and reports whether it did before the timeout
+func (vc *VoiceConnection) WaitDisconnect(timeout time.Duration) bool {
+	select {
+	case <-vc.done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// transport is the transport of the harness which opens fake voice connections
+type transport struct {
+	fuzzy.Transport
+	h *Harness
+}
+
+func (t transport) ChannelVoiceJoin(guildID, channelID string, mute, deaf bool) (fuzzy.VoiceConnection, error) {
+	return t.h.joinVoice(guildID, channelID)
+}
+
+// transportGenerator creates the transport of the harness on the default transport
+func (h *Harness) transportGenerator(s *discordgo.Session) fuzzy.Transport {
+	return transport{Transport: fuzzy.DefaultTransport(s), h: h}
+}
+
+// joinVoice opens a fake voice connection or fails with the error set by FailVoiceJoin
+func (h *Harness) joinVoice(guildID, channelID string) (fuzzy.VoiceConnection, error) {
+	h.mu.Lock()
+	err := h.joinErr
+	h.joinErr = nil
+	h.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	vc := &VoiceConnection{
+		GuildID:   guildID,
+		ChannelID: channelID,
+		h:         h,
+		send:      make(chan []byte),
+		done:      make(chan struct{}),
+	}
+	h.record(Action{Type: ActionVoiceJoin, GuildID: guildID, ChannelID: channelID})
+
+	h.mu.Lock()
+	h.voiceConns[guildID] = vc
+	h.mu.Unlock()
+	return vc, nil
+}
+
+// FailVoiceJoin makes the next voice channel join fail with the error
+func (h *Harness) FailVoiceJoin(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.joinErr = err
+}
+
+// UseDefaultVoiceHandler makes the bot play with fuzzy.DefaultVoiceHandler on fake voice connections
+// instead of recording the played items right away
+func (h *Harness) UseDefaultVoiceHandler() {
+	h.Bot.Generator().SetVoiceHandlerGenerator(fuzzy.DefaultVoiceHandler)
+}
+
+// VoiceConnection waits until the bot has opened a voice connection in the guild and returns the last one
+func (h *Harness) VoiceConnection(guildID string, timeout time.Duration) (*VoiceConnection, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		h.mu.Lock()
+		vc, ok := h.voiceConns[guildID]
+		h.mu.Unlock()
+		if ok {
+			return vc, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, ErrNoVoiceConnection
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,284 @@
+package fuzzytest_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fvdveen/fuzzy"
+	"github.com/fvdveen/fuzzy/fuzzytest"
+)
+
+const (
+	gid  = fuzzytest.DefaultGuildID
+	wait = time.Second
+)
+
+func newVoiceHarness(t *testing.T) *fuzzytest.Harness {
+	h, err := fuzzytest.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.UseDefaultVoiceHandler()
+	return h
+}
+
+func item(fs ...byte) *frames {
+	var item frames
+	for _, f := range fs {
+		item.frames = append(item.frames, []byte{f})
+	}
+	return &item
+}
+
+func play(h *fuzzytest.Harness, fs ...byte) *frames {
+	vi := item(fs...)
+	h.Bot.PlaySound(gid, fuzzytest.DefaultVoiceChannelID, fuzzytest.DefaultChannelID, vi)
+	return vi
+}
+
+// receive receives n frames and returns their first bytes
+func receive(t *testing.T, vc *fuzzytest.VoiceConnection, n int) []byte {
+	t.Helper()
+
+	fs, err := vc.Receive(n, wait)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var bs []byte
+	for _, f := range fs {
+		bs = append(bs, f.Data[0])
+	}
+	return bs
+}
+
+func connection(t *testing.T, h *fuzzytest.Harness) (*fuzzytest.VoiceConnection, fuzzy.VoiceHandler) {
+	t.Helper()
+
+	vc, err := h.VoiceConnection(gid, wait)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vh, err := h.Bot.VoiceHandler(gid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return vc, vh
+}
+
+// waitRemoved waits until the bot has removed the voice handler of the guild
+func waitRemoved(t *testing.T, h *fuzzytest.Harness) {
+	t.Helper()
+
+	for deadline := time.Now().Add(wait); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		if _, err := h.Bot.VoiceHandler(gid); err == fuzzy.ErrVoiceHandlerNotExists {
+			return
+		}
+	}
+	t.Fatal("expected the voice handler to be removed")
+}
+
+func TestVoiceSkip(t *testing.T) {
+	h := newVoiceHarness(t)
+	play(h, 1, 2, 3)
+	play(h, 4, 5)
+	vc, vh := connection(t, h)
+
+	if fs := receive(t, vc, 1); !reflect.DeepEqual(fs, []byte{1}) {
+		t.Fatalf("expected frame 1 got %v", fs)
+	}
+	vh.Skip()
+	if fs := receive(t, vc, 2); !reflect.DeepEqual(fs, []byte{4, 5}) {
+		t.Fatalf("expected frames 4 and 5 got %v", fs)
+	}
+	if !vc.WaitDisconnect(wait) {
+		t.Fatal("expected the bot to disconnect after the queue is empty")
+	}
+	waitRemoved(t, h)
+
+	start := time.Unix(0, 0).UTC()
+	for i, f := range vc.Frames() {
+		if exp := start.Add(time.Duration(i) * fuzzytest.FrameDuration); !f.Time.Equal(exp) {
+			t.Fatalf("expected frame %d at %v got %v", i, exp, f.Time)
+		}
+	}
+}
+
+func TestVoicePause(t *testing.T) {
+	h := newVoiceHarness(t)
+	play(h, 1, 2, 3)
+	vc, vh := connection(t, h)
+
+	receive(t, vc, 2)
+	vh.Pause()
+	if fs, _ := vc.Receive(1, 50*time.Millisecond); len(fs) != 0 {
+		t.Fatalf("expected no frames while paused got %v", fs)
+	}
+	h.Clock.Advance(time.Minute)
+	vh.Resume()
+
+	fs, err := vc.Receive(1, wait)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := time.Unix(60, int64(2*fuzzytest.FrameDuration)).UTC(); fs[0].Data[0] != 3 || !fs[0].Time.Equal(exp) {
+		t.Fatalf("expected frame 3 at %v got %v", exp, fs[0])
+	}
+	if !vc.WaitDisconnect(wait) {
+		t.Fatal("expected the bot to disconnect")
+	}
+}
+
+func TestVoiceLoopRepeat(t *testing.T) {
+	h := newVoiceHarness(t)
+	play(h, 1, 2)
+	play(h, 3)
+	vc, vh := connection(t, h)
+
+	vh.Repeat()
+	if fs := receive(t, vc, 5); !reflect.DeepEqual(fs, []byte{1, 2, 1, 2, 1}) {
+		t.Fatalf("expected the first item to repeat got %v", fs)
+	}
+	vh.Repeat()
+	vh.Loop()
+	if fs := receive(t, vc, 6); !reflect.DeepEqual(fs, []byte{2, 3, 1, 2, 3, 1}) {
+		t.Fatalf("expected the queue to loop got %v", fs)
+	}
+
+	vh.Stop()
+	if !vc.WaitDisconnect(wait) {
+		t.Fatal("expected the bot to disconnect after stopping")
+	}
+	waitRemoved(t, h)
+}
+
+func TestVoiceJoinFailure(t *testing.T) {
+	h := newVoiceHarness(t)
+	h.FailVoiceJoin(errors.New("no permission"))
+	play(h, 1)
+	waitRemoved(t, h)
+	if _, err := h.VoiceConnection(gid, 50*time.Millisecond); err != fuzzytest.ErrNoVoiceConnection {
+		t.Fatalf("expected no voice connection got %v", err)
+	}
+
+	play(h, 1)
+	vc, _ := connection(t, h)
+	if fs := receive(t, vc, 1); !reflect.DeepEqual(fs, []byte{1}) {
+		t.Fatalf("expected the second join to play got %v", fs)
+	}
+}
+
+func TestVoiceDrop(t *testing.T) {
+	h := newVoiceHarness(t)
+	play(h, 1, 2, 3)
+	vc, vh := connection(t, h)
+
+	receive(t, vc, 1)
+	if !vc.IsSpeaking() {
+		t.Fatal("expected the bot to be speaking")
+	}
+	vc.Drop()
+	if _, err := vc.Receive(1, wait); err != fuzzytest.ErrVoiceDisconnected {
+		t.Fatalf("expected the connection to be dropped got %v", err)
+	}
+	if err := vc.Speaking(true); err != fuzzytest.ErrVoiceDisconnected {
+		t.Fatalf("expected speaking to fail got %v", err)
+	}
+
+	// the bot is not told about the drop, it keeps waiting to send the next frame until it is stopped
+	if vc.WaitDisconnect(10 * time.Millisecond) {
+		t.Fatal("expected the bot to stay connected")
+	}
+	if vh.NowPlaying() == nil {
+		t.Fatal("expected the item to still be playing")
+	}
+	vh.Stop()
+	if !vc.WaitDisconnect(wait) {
+		t.Fatal("expected the bot to disconnect after stopping")
+	}
+	waitRemoved(t, h)
+}
+
+func TestVoiceQueue(t *testing.T) {
+	h := newVoiceHarness(t)
+	first := play(h, 1, 1)
+	two, three, four := play(h, 2), play(h, 3), play(h, 4)
+	vc, vh := connection(t, h)
+
+	// the first item is playing once its first frame is received
+	receive(t, vc, 1)
+	if vi := vh.NowPlaying(); vi != first {
+		t.Fatalf("expected the first item to be playing got %v", vi)
+	}
+	if q := vh.Queue(); !reflect.DeepEqual(q, []fuzzy.VoiceItem{two, three, four}) {
+		t.Fatalf("expected the other items to be queued got %v", q)
+	}
+
+	five, six := item(5, 5), item(6)
+	for _, f := range []func() error{
+		func() error { return vh.Move(2, 0) },
+		func() error { return vh.Remove(1) },
+		func() error { return vh.Insert(1, five) },
+		func() error { vh.PlayNext(six); return nil },
+	} {
+		if err := f(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := vh.Remove(4); err != fuzzy.ErrQueueIndex {
+		t.Fatalf("expected %v got %v", fuzzy.ErrQueueIndex, err)
+	}
+	if fs := receive(t, vc, 4); !reflect.DeepEqual(fs, []byte{1, 6, 4, 5}) {
+		t.Fatalf("expected the changed queue to play got %v", fs)
+	}
+
+	vh.Clear()
+	if fs := receive(t, vc, 1); !reflect.DeepEqual(fs, []byte{5}) {
+		t.Fatalf("expected the current item to finish got %v", fs)
+	}
+	if !vc.WaitDisconnect(wait) {
+		t.Fatal("expected the bot to disconnect after the queue is cleared")
+	}
+}
+
+type song struct {
+	*frames
+}
+
+func (song) Metadata() fuzzy.Metadata {
+	return fuzzy.Metadata{Title: "Song", Duration: time.Minute, RequesterID: fuzzytest.DefaultUserID}
+}
+
+func TestNowPlaying(t *testing.T) {
+	h := newVoiceHarness(t)
+	if err := h.Bot.RegisterCommand(fuzzy.NowPlayingCommand()); err != nil {
+		t.Fatal(err)
+	}
+
+	h.Say("!np")
+	if m := h.LastMessage(); m == nil || len(m.Embeds) != 1 || m.Embeds[0].Description != fuzzy.ErrNothingPlaying.Error() {
+		t.Fatalf("expected nothing to be playing got %v", m)
+	}
+
+	h.Bot.PlaySound(gid, fuzzytest.DefaultVoiceChannelID, fuzzytest.DefaultChannelID, song{item(1, 2, 3)})
+	vc, vh := connection(t, h)
+	receive(t, vc, 1)
+
+	h.Say("!nowplaying")
+	m := h.LastMessage()
+	if m == nil || len(m.Embeds) != 1 || m.Embeds[0].Title != "Now playing" {
+		t.Fatalf("expected a now playing embed got %v", m)
+	}
+	if exp := "Song\n" + fuzzy.ProgressBar(0, time.Minute, fuzzy.ProgressBarWidth); m.Embeds[0].Description != exp {
+		t.Fatalf("expected description %q got %q", exp, m.Embeds[0].Description)
+	}
+
+	receive(t, vc, 1)
+	// the position is counted once the frame is sent
+	for deadline := time.Now().Add(wait); vh.Position() < 2*fuzzytest.FrameDuration; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected a position of 2 frames got %v", vh.Position())
+		}
+	}
+}
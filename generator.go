@@ -5,6 +5,7 @@ type Generator struct {
 	contextGenerator      ContextGenerator
 	voiceHandlerGenerator VoiceHandlerGenerator
 	loggerGenerator       LoggerGenerator
+	transportGenerator    TransportGenerator
 }
 
 // DefaultGenerator is the default generator for the bot
@@ -13,6 +14,7 @@ func DefaultGenerator() *Generator {
 		contextGenerator:      ContextGenerator(DefaultContext),
 		loggerGenerator:       LoggerGenerator(DefaultLogger),
 		voiceHandlerGenerator: VoiceHandlerGenerator(DefaultVoiceHandler),
+		transportGenerator:    TransportGenerator(DefaultTransport),
 	}
 
 	return g
@@ -33,6 +35,11 @@ func (g *Generator) SetLoggerGenerator(l LoggerGenerator) {
 	g.loggerGenerator = l
 }
 
+// SetTransportGenerator sets the Transport generator
+func (g *Generator) SetTransportGenerator(t TransportGenerator) {
+	g.transportGenerator = t
+}
+
 // Logger creates a new logger
 func (g *Generator) Logger(lvl LogLevel) Logger {
 	return g.loggerGenerator(lvl)
@@ -2,31 +2,101 @@ package fuzzy
 
 import (
 	"context"
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 )
 
 func (b *Bot) initHandlers() {
-	b.RegisterHandler(b.messageHandler())
+	b.sess.AddHandler(func(s *discordgo.Session, e interface{}) {
+		b.events.Publish(e)
+	})
+
+	_, _ = b.events.Subscribe(b.messageHandler, Priority(frameworkPriority))
+	_, _ = b.events.Subscribe(b.reactionHandler, Priority(frameworkPriority))
 }
 
-func (b *Bot) messageHandler() func(*discordgo.Session, *discordgo.MessageCreate) {
-	return func(s *discordgo.Session, m *discordgo.MessageCreate) {
-		if s.State.User.ID == m.Author.ID {
-			return
+func (b *Bot) messageHandler(_ *Bot, m *discordgo.MessageCreate) {
+	if b.Transport().BotUser().ID == m.Author.ID {
+		return
+	}
+	if b.deliverReply(m) {
+		return
+	}
+	prefix := b.Prefix(m.GuildID)
+	if !strings.HasPrefix(m.Content, prefix) {
+		return
+	}
+	msg := strings.TrimPrefix(m.Content, prefix)
+	com, msg := b.matchCommand(msg)
+	if com == nil {
+		return
+	}
+
+	c, cancel := context.WithCancel(context.WithValue(context.Background(), commandKey{}, true))
+	defer cancel()
+	b.activity.add(1)
+	defer b.activity.add(-1)
+	b.runCommand(b.generator.contextGenerator(c, msg, m, b, b.sess, com), com)
+}
+
+// runCommand runs the command through the middleware when all checks pass
+// it publishes CommandExecuted when the command ran and CommandFailed when it did not pass its checks or panicked
+func (b *Bot) runCommand(ctx Context, com Command) {
+	defer func() {
+		if r := recover(); r != nil {
+			err := fmt.Errorf("command %s panicked: %v", com.Name(), r)
+			ctx.Logger().Errorf("%v", err)
+			b.events.Publish(&CommandFailed{Context: ctx, Err: err})
 		}
-		if !strings.HasPrefix(m.Content, b.conf.Prefix) {
+	}()
+
+	var (
+		ran   bool
+		start time.Time
+	)
+	h := CommandHandlerFunc(func(ctx Context) {
+		if err := b.CanRun(ctx, com); err == ErrCommandDisabled {
+			return
+		} else if err != nil {
+			b.events.Publish(&CommandFailed{Context: ctx, Err: err})
+			if _, err := ctx.SendError(err); err != nil {
+				ctx.Logger().Errorf("Could not send check error: %v", err)
+			}
 			return
 		}
-		msg := strings.TrimPrefix(m.Content, b.conf.Prefix)
-		for _, com := range b.commands {
-			if msg == com.Name() || strings.HasPrefix(msg, com.Name()+" ") {
-				msg = strings.TrimSpace(strings.TrimPrefix(msg, com.Name()))
-				ctx := b.generator.contextGenerator(context.Background(), msg, m, b, s, com)
-				b.middleware.Then(com).Handle(ctx)
-				return
+
+		ran = true
+		start = time.Now()
+		com.Handle(ctx)
+	})
+
+	b.middleware.Then(h).Handle(ctx)
+	if ran {
+		b.events.Publish(&CommandExecuted{Context: ctx, Duration: time.Since(start)})
+	}
+}
+
+// matchCommand finds the command triggered by the message and returns it with its arguments
+func (b *Bot) matchCommand(msg string) (Command, string) {
+	b.commandsMu.RLock()
+	defer b.commandsMu.RUnlock()
+
+	for _, com := range b.commands {
+		for _, n := range commandNames(com) {
+			if msg == n || strings.HasPrefix(msg, n+" ") {
+				return com, strings.TrimSpace(strings.TrimPrefix(msg, n))
 			}
 		}
 	}
+	return nil, ""
+}
+
+func (b *Bot) reactionHandler(_ *Bot, r *discordgo.MessageReactionAdd) {
+	if b.Transport().BotUser().ID == r.UserID {
+		return
+	}
+	b.deliverReaction(r)
 }
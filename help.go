@@ -0,0 +1,194 @@
+package fuzzy
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// helpCategoriesPerPage is the maximum amount of categories shown on a single help page
+	helpCategoriesPerPage = 6
+
+	// defaultCategory is the category of commands without one
+	defaultCategory = "Other"
+)
+
+// HelpCommand is a standard help command
+// "help" lists the commands the user can run by category and "help <command>" shows the details of a command
+func HelpCommand(t, d string) Command {
+	return NewCommand("help", "Shows all commands", func(ctx Context) {
+		var err error
+		if ctx.Message() == "" {
+			err = sendHelp(ctx, t, d)
+		} else {
+			err = sendCommandHelp(ctx, ctx.Message())
+		}
+		if err != nil {
+			ctx.Logger().Errorf("Could not send help: %v", err)
+		}
+	},
+		WithUsage("[command]"),
+		WithParameters(Parameter{
+			Name:        "command",
+			Description: "The command to show the details of",
+			Optional:    true,
+		}),
+	)
+}
+
+// sendHelp sends the commands the user can run divided by category
+func sendHelp(ctx Context, t, d string) error {
+	b := ctx.Bot()
+	prefix := b.Prefix(ctx.MessageEvent().GuildID)
+
+	cats := map[string][]string{}
+	for _, com := range visibleCommands(ctx) {
+		cat := Details(com).Category
+		if cat == "" {
+			cat = defaultCategory
+		}
+		cats[cat] = append(cats[cat], fmt.Sprintf("`%s%s` %s", prefix, com.Name(), com.Description()))
+	}
+
+	names := make([]string, 0, len(cats))
+	for c := range cats {
+		if c != defaultCategory {
+			names = append(names, c)
+		}
+	}
+	sort.Strings(names)
+	if _, ok := cats[defaultCategory]; ok {
+		names = append(names, defaultCategory)
+	}
+
+	// pages are filled up to the size discord allows, so Build never has to drop fields
+	// room is left for the page number the paginator adds to the footer
+	var (
+		pages []Page
+		e     *EmbedBuilder
+		n     int
+	)
+	newPage := func() {
+		if e != nil {
+			pages = append(pages, Page{Embed: e.Build()})
+		}
+		e = b.NewEmbed().
+			SetTitle(fmt.Sprintf("%s - Commands", t)).
+			SetDescription(d).
+			SetFooter(fmt.Sprintf("Use %shelp <command> for more information about a command", prefix), "")
+		n = 0
+	}
+	newPage()
+	for _, name := range names {
+		if n >= helpCategoriesPerPage {
+			newPage()
+		}
+		for _, v := range fieldValues(cats[name]) {
+			full := e.FieldCount() >= EmbedFieldLimit ||
+				e.Length()+utf8.RuneCountInString(name)+utf8.RuneCountInString(v) > EmbedTotalLimit-maxPageNumberLength
+			if full && e.FieldCount() > 0 {
+				newPage()
+			}
+			e.AddField(name, v, false)
+		}
+		n++
+	}
+	pages = append(pages, Page{Embed: e.Build()})
+
+	return NewPaginator(pages...).Send(ctx)
+}
+
+// sendCommandHelp sends the details of a single command
+func sendCommandHelp(ctx Context, name string) error {
+	b := ctx.Bot()
+	prefix := b.Prefix(ctx.MessageEvent().GuildID)
+	name = strings.TrimPrefix(name, prefix)
+
+	var com Command
+	for _, c := range visibleCommands(ctx) {
+		for _, n := range commandNames(c) {
+			if n == name {
+				com = c
+			}
+		}
+	}
+	if com == nil {
+		_, err := ctx.SendError(fmt.Errorf("unknown command: %s", name))
+		return err
+	}
+
+	det := Details(com)
+	e := b.NewEmbed().
+		SetTitle(prefix+com.Name()).
+		SetDescription(com.Description()).
+		AddField("Usage", fmt.Sprintf("`%s`", strings.TrimSpace(prefix+com.Name()+" "+det.Usage)), false)
+
+	if len(det.Parameters) > 0 {
+		var ps []string
+		for _, p := range det.Parameters {
+			opt := ""
+			if p.Optional {
+				opt = " (optional)"
+			}
+			ps = append(ps, fmt.Sprintf("`%s`%s %s", p.Name, opt, p.Description))
+		}
+		e.AddField("Parameters", strings.Join(ps, "\n"), false)
+	}
+	if len(det.Aliases) > 0 {
+		e.AddField("Aliases", "`"+strings.Join(det.Aliases, "`, `")+"`", false)
+	}
+	if len(det.Examples) > 0 {
+		var es []string
+		for _, ex := range det.Examples {
+			es = append(es, fmt.Sprintf("`%s%s`", prefix, ex))
+		}
+		e.AddField("Examples", strings.Join(es, "\n"), false)
+	}
+	if det.Category != "" {
+		e.AddField("Category", det.Category, true)
+	}
+
+	_, err := ctx.SendEmbed(e.Build())
+	return err
+}
+
+// visibleCommands returns the commands that are not hidden and the user can run
+// the permissions of the user are looked up once for all commands
+func visibleCommands(ctx Context) []Command {
+	ctx = withPermissionsCache(ctx)
+	var cs []Command
+	for _, com := range ctx.Bot().Commands() {
+		if Details(com).Hidden {
+			continue
+		}
+		if err := ctx.Bot().CanRun(ctx, com); err != nil {
+			continue
+		}
+		cs = append(cs, com)
+	}
+	return cs
+}
+
+// fieldValues joins the lines into as few embed field values as possible
+func fieldValues(lines []string) []string {
+	var (
+		vs  []string
+		cur string
+	)
+	for _, l := range lines {
+		if cur != "" && len(cur)+len(l)+1 > EmbedFieldValueLimit {
+			vs = append(vs, cur)
+			cur = ""
+		}
+		if cur != "" {
+			cur += "\n"
+		}
+		cur += l
+	}
+	if cur != "" {
+		vs = append(vs, cur)
+	}
+	return vs
+}
@@ -0,0 +1,138 @@
+package fuzzy_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/fvdveen/fuzzy"
+	"github.com/fvdveen/fuzzy/fuzzytest"
+)
+
+func TestHelpPages(t *testing.T) {
+	h, err := fuzzytest.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := []fuzzy.Command{fuzzy.HelpCommand("bot", "all commands")}
+	for i := 0; i < 200; i++ {
+		cs = append(cs, fuzzy.NewCommand(fmt.Sprintf("command%d", i), strings.Repeat("d", 100), func(fuzzy.Context) {}, fuzzy.WithCategory("Big")))
+	}
+	if err := h.Bot.RegisterCommand(cs...); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := h.SendIdle(fuzzytest.DefaultChannelID, fuzzytest.DefaultUserID, "!help"); err != nil {
+		t.Fatal(err)
+	}
+	m := h.LastMessage()
+	if m == nil || len(m.Embeds) != 1 {
+		t.Fatalf("expected a help embed got %v", m)
+	}
+
+	var text strings.Builder
+	pages := 0
+	for e := m.Embeds[0]; ; {
+		pages++
+		if !strings.Contains(e.Footer.Text, fmt.Sprintf(" | Page %d/", pages)) {
+			t.Fatalf("expected the page number in the footer got %q", e.Footer.Text)
+		}
+		if !strings.HasPrefix(e.Footer.Text, "Use !help <command>") {
+			t.Fatalf("expected the footer of the help page to be kept got %q", e.Footer.Text)
+		}
+		l := utf8.RuneCountInString(e.Title) + utf8.RuneCountInString(e.Description) + utf8.RuneCountInString(e.Footer.Text)
+		for _, f := range e.Fields {
+			l += utf8.RuneCountInString(f.Name) + utf8.RuneCountInString(f.Value)
+			text.WriteString(f.Value + "\n")
+		}
+		if len(e.Fields) > fuzzy.EmbedFieldLimit || l > fuzzy.EmbedTotalLimit {
+			t.Fatalf("page %d exceeds the embed limits with %d fields and %d characters", pages, len(e.Fields), l)
+		}
+
+		if err := h.ReactIdle(fuzzytest.DefaultChannelID, m.ID, fuzzytest.DefaultUserID, fuzzy.PageNext); err != nil {
+			t.Fatal(err)
+		}
+		next := h.CurrentMessage(m.ID).Embeds[0]
+		if next == e {
+			break
+		}
+		e = next
+	}
+
+	if pages < 2 {
+		t.Fatalf("expected the commands to be split over multiple pages got %d", pages)
+	}
+	for _, c := range cs {
+		if !strings.Contains(text.String(), "`!"+c.Name()+"` ") {
+			t.Errorf("expected command %s on a page", c.Name())
+		}
+	}
+}
+
+// permissionsTransport counts the permission lookups and records the sent messages
+type permissionsTransport struct {
+	fuzzy.Transport
+	lookups int
+	sent    []*discordgo.MessageSend
+}
+
+func (t *permissionsTransport) UserChannelPermissions(userID, channelID string) (int64, error) {
+	t.lookups++
+	return discordgo.PermissionSendMessages, nil
+}
+
+func (t *permissionsTransport) BotUser() *discordgo.User {
+	return &discordgo.User{ID: "1000", Bot: true}
+}
+
+func (t *permissionsTransport) ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend) (*discordgo.Message, error) {
+	t.sent = append(t.sent, data)
+	return &discordgo.Message{ID: "5000", ChannelID: channelID}, nil
+}
+
+func TestHelpPermissions(t *testing.T) {
+	pt := &permissionsTransport{}
+	b, err := fuzzy.New(
+		fuzzy.WithConfig(&fuzzy.Config{Token: "token", Prefix: "!", LogLevel: fuzzy.LogError}),
+		func(b *fuzzy.Bot) {
+			b.Generator().SetTransportGenerator(func(s *discordgo.Session) fuzzy.Transport {
+				pt.Transport = fuzzy.DefaultTransport(s)
+				return pt
+			})
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.RegisterCommand(
+		fuzzy.HelpCommand("bot", "all commands"),
+		fuzzy.NewCommand("say", "", func(fuzzy.Context) {}, fuzzy.WithPermissions(discordgo.PermissionSendMessages)),
+		fuzzy.NewCommand("kick", "", func(fuzzy.Context) {}, fuzzy.WithPermissions(discordgo.PermissionKickMembers)),
+		fuzzy.NewCommand("ban", "", func(fuzzy.Context) {}, fuzzy.WithPermissions(discordgo.PermissionBanMembers)),
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	b.Inject(&discordgo.MessageCreate{Message: &discordgo.Message{
+		ID:        "6000",
+		GuildID:   "2000",
+		ChannelID: "3000",
+		Content:   "!help",
+		Author:    &discordgo.User{ID: "4000"},
+	}})
+	if pt.lookups != 1 {
+		t.Fatalf("expected the permissions to be looked up once got %d lookups", pt.lookups)
+	}
+	if len(pt.sent) != 1 || pt.sent[0].Embed == nil {
+		t.Fatalf("expected a help embed got %v", pt.sent)
+	}
+	var text strings.Builder
+	for _, f := range pt.sent[0].Embed.Fields {
+		text.WriteString(f.Value + "\n")
+	}
+	if !strings.Contains(text.String(), "`!say`") || strings.Contains(text.String(), "`!kick`") || strings.Contains(text.String(), "`!ban`") {
+		t.Fatalf("expected only the commands the user may run got %q", text.String())
+	}
+}
@@ -0,0 +1,100 @@
+package fuzzy
+
+import (
+	"context"
+	"sync"
+)
+
+// commandKey marks the context of a command so the waits of its handler are counted by the bot's activity
+type commandKey struct{}
+
+// activity counts the command handlers which are running and not waiting for a reply or reaction
+type activity struct {
+	mu      sync.Mutex
+	running int
+	// waits counts how often a command handler started waiting
+	waits uint64
+	// changed is closed when the counters change
+	changed chan struct{}
+}
+
+// add changes the amount of running command handlers
+func (a *activity) add(n int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.running += n
+	a.notify()
+}
+
+// wait marks a running command handler as waiting
+func (a *activity) wait() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.running--
+	a.waits++
+	a.notify()
+}
+
+func (a *activity) notify() {
+	if a.changed != nil {
+		close(a.changed)
+		a.changed = nil
+	}
+}
+
+// state returns the counters and a channel which is closed when they change
+func (a *activity) state() (int, uint64, <-chan struct{}) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.changed == nil {
+		a.changed = make(chan struct{})
+	}
+	return a.running, a.waits, a.changed
+}
+
+// tracked reports whether the context belongs to a command
+func tracked(ctx context.Context) bool {
+	return ctx.Value(commandKey{}) != nil
+}
+
+// InjectIdle publishes the event like Inject but returns once the bot is idle:
+// when the handlers of the event are done or the command handlers that are running all wait for a reply or reaction
+// this lets tests and the console send the answer of a dialog once the dialog is waiting for it
+// it returns ctx.Err() when ctx is done before the bot is idle
+func (b *Bot) InjectIdle(ctx context.Context, e interface{}) error {
+	_, waits, _ := b.activity.state()
+	return b.waitIdle(ctx, waits, b.publishAsync(e))
+}
+
+// publishAsync publishes the event in the background, the returned channel is closed when its handlers are done
+func (b *Bot) publishAsync(e interface{}) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		b.events.Publish(e)
+	}()
+	return done
+}
+
+// waitIdle waits until no command handler is running
+// and the handlers of the event are done or a command handler has started waiting since waits was read
+func (b *Bot) waitIdle(ctx context.Context, start uint64, done <-chan struct{}) error {
+	published := false
+	for {
+		running, waits, changed := b.activity.state()
+		if running <= 0 && (published || waits != start) {
+			return nil
+		}
+
+		select {
+		case <-done:
+			published, done = true, nil
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
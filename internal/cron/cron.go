@@ -0,0 +1,201 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed cron spec
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+
+	// domStar and dowStar are set when the day fields are *
+	domStar, dowStar bool
+}
+
+type bounds struct {
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minutes = bounds{0, 59, nil}
+	hours   = bounds{0, 23, nil}
+	doms    = bounds{1, 31, nil}
+	months  = bounds{1, 12, map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dows = bounds{0, 7, map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse parses a standard 5 field cron spec (minute hour day-of-month month day-of-week)
+// fields support *, lists, ranges, steps and month and weekday names
+// the descriptors @yearly, @monthly, @weekly, @daily and @hourly are also supported
+func Parse(spec string) (*Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if d, ok := descriptors[strings.ToLower(spec)]; ok {
+		spec = d
+	}
+
+	fs := strings.Fields(spec)
+	if len(fs) != 5 {
+		return nil, ErrFieldCount
+	}
+
+	s := &Schedule{
+		domStar: fs[2] == "*" || fs[2] == "?",
+		dowStar: fs[4] == "*" || fs[4] == "?",
+	}
+	var err error
+	if s.minute, err = parseField(fs[0], minutes); err != nil {
+		return nil, err
+	}
+	if s.hour, err = parseField(fs[1], hours); err != nil {
+		return nil, err
+	}
+	if s.dom, err = parseField(fs[2], doms); err != nil {
+		return nil, err
+	}
+	if s.month, err = parseField(fs[3], months); err != nil {
+		return nil, err
+	}
+	if s.dow, err = parseField(fs[4], dows); err != nil {
+		return nil, err
+	}
+	// 7 is also sunday
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+
+	return s, nil
+}
+
+// parseField parses a single comma separated field into a bitset
+func parseField(f string, b bounds) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(f, ",") {
+		x, err := parseRange(part, b)
+		if err != nil {
+			return 0, fmt.Errorf("%v: %q: %v", ErrInvalidField, f, err)
+		}
+		bits |= x
+	}
+	return bits, nil
+}
+
+// parseRange parses a *, a single value or a range with an optional step
+func parseRange(r string, b bounds) (uint64, error) {
+	step := 1
+	if i := strings.Index(r, "/"); i >= 0 {
+		var err error
+		step, err = strconv.Atoi(r[i+1:])
+		if err != nil || step <= 0 {
+			return 0, fmt.Errorf("invalid step %q", r[i+1:])
+		}
+		r = r[:i]
+	}
+
+	var start, end int
+	switch {
+	case r == "*" || r == "?":
+		start, end = b.min, b.max
+	case strings.Contains(r, "-"):
+		i := strings.Index(r, "-")
+		var err error
+		if start, err = parseValue(r[:i], b); err != nil {
+			return 0, err
+		}
+		if end, err = parseValue(r[i+1:], b); err != nil {
+			return 0, err
+		}
+	default:
+		var err error
+		if start, err = parseValue(r, b); err != nil {
+			return 0, err
+		}
+		end = start
+		if step > 1 {
+			end = b.max
+		}
+	}
+	if start > end {
+		return 0, fmt.Errorf("range %d-%d is backwards", start, end)
+	}
+
+	var bits uint64
+	for i := start; i <= end; i += step {
+		bits |= 1 << uint(i)
+	}
+	return bits, nil
+}
+
+func parseValue(v string, b bounds) (int, error) {
+	if n, ok := b.names[strings.ToLower(v)]; ok {
+		return n, nil
+	}
+
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", v)
+	}
+	if i < b.min || i > b.max {
+		return 0, fmt.Errorf("value %d out of range %d-%d", i, b.min, b.max)
+	}
+	return i, nil
+}
+
+// Next returns the first time after t that matches the schedule
+// it returns the zero time if there is no such time within 5 years
+func (s *Schedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+
+	return time.Time{}
+}
+
+// dayMatches checks the day of the month and the day of the week
+// like cron a day matches either of them when both are restricted
+func (s *Schedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
@@ -0,0 +1,32 @@
+package cron_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fvdveen/fuzzy/internal/cron"
+)
+
+func TestNext(t *testing.T) {
+	for _, test := range nextTests {
+		s, err := cron.Parse(test.spec)
+		if err != nil {
+			t.Errorf("%s: %v", test.spec, err)
+			continue
+		}
+
+		from, _ := time.Parse(time.RFC3339, test.from)
+		exp, _ := time.Parse(time.RFC3339, test.next)
+		if next := s.Next(from); !next.Equal(exp) {
+			t.Errorf("%s from %s: expected: %s got: %s", test.spec, test.from, exp, next)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, spec := range invalidSpecs {
+		if _, err := cron.Parse(spec); err == nil {
+			t.Errorf("%s: expected error", spec)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+package cron
+
+import "errors"
+
+var (
+	// ErrFieldCount is used when a spec does not have 5 fields
+	ErrFieldCount = errors.New("cron spec must have 5 fields")
+
+	// ErrInvalidField is used when a field of a spec can not be parsed
+	ErrInvalidField = errors.New("invalid cron field")
+)
@@ -0,0 +1,33 @@
+package cron_test
+
+var nextTests = []struct {
+	spec string
+	from string
+	next string
+}{
+	{"* * * * *", "2020-01-01T10:00:30Z", "2020-01-01T10:01:00Z"},
+	{"0 9 * * *", "2020-01-01T10:00:00Z", "2020-01-02T09:00:00Z"},
+	{"0 9 * * *", "2020-01-01T08:59:00Z", "2020-01-01T09:00:00Z"},
+	{"*/15 * * * *", "2020-01-01T10:07:00Z", "2020-01-01T10:15:00Z"},
+	{"30 8-10 * * mon-fri", "2020-01-03T10:30:00Z", "2020-01-06T08:30:00Z"},
+	{"0 0 1 jan *", "2020-06-15T12:00:00Z", "2021-01-01T00:00:00Z"},
+	{"0 0 29 2 *", "2020-03-01T00:00:00Z", "2024-02-29T00:00:00Z"},
+	{"0 12 13 * 5", "2020-01-01T00:00:00Z", "2020-01-03T12:00:00Z"},
+	{"0 0 * * 7", "2020-01-01T00:00:00Z", "2020-01-05T00:00:00Z"},
+	{"5,10 0 * * *", "2020-01-01T00:05:00Z", "2020-01-01T00:10:00Z"},
+	{"@hourly", "2020-01-01T10:15:00Z", "2020-01-01T11:00:00Z"},
+	{"@daily", "2020-12-31T10:15:00Z", "2021-01-01T00:00:00Z"},
+}
+
+var invalidSpecs = []string{
+	"",
+	"* * * *",
+	"60 * * * *",
+	"* 24 * * *",
+	"* * 0 * *",
+	"* * * 13 *",
+	"* * * * 8",
+	"*/0 * * * *",
+	"5-1 * * * *",
+	"a * * * *",
+}
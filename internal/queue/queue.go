@@ -1,6 +1,7 @@
 package queue
 
 import (
+	"math/rand"
 	"sync"
 )
 
@@ -46,14 +47,11 @@ func (q *Queue) PushBack(i ...interface{}) {
 
 // PopBack returns the last element of the queue and removes it
 func (q *Queue) PopBack() interface{} {
-	q.mu.RLock()
+	q.mu.Lock()
+	defer q.mu.Unlock()
 	if len(q.is) == 0 {
-		q.mu.RUnlock()
 		return nil
 	}
-	q.mu.RUnlock()
-	q.mu.Lock()
-	defer q.mu.Unlock()
 	l := len(q.is) - 1
 	i := q.is[l]
 	q.is = q.is[:l]
@@ -80,14 +78,11 @@ func (q *Queue) PushFront(i ...interface{}) {
 
 // PopFront returns the first element of the queue and removes it
 func (q *Queue) PopFront() interface{} {
-	q.mu.RLock()
+	q.mu.Lock()
+	defer q.mu.Unlock()
 	if len(q.is) == 0 {
-		q.mu.RUnlock()
 		return nil
 	}
-	q.mu.RUnlock()
-	q.mu.Lock()
-	defer q.mu.Unlock()
 	i := q.is[0]
 	q.is = q.is[1:]
 	return i
@@ -95,19 +90,14 @@ func (q *Queue) PopFront() interface{} {
 
 // Reorder puts element a at elements b position in the queue
 func (q *Queue) Reorder(a, b int) error {
-	q.mu.RLock()
+	q.mu.Lock()
+	defer q.mu.Unlock()
 	if a < 0 || b < 0 || a > len(q.is)-1 || b > len(q.is)-1 {
-		q.mu.RUnlock()
 		return ErrOutOfBounds
 	}
 	if a == b {
-		q.mu.RUnlock()
 		return nil
 	}
-	q.mu.RUnlock()
-
-	q.mu.Lock()
-	defer q.mu.Unlock()
 
 	i := q.is[a]
 	q.is = append(q.is[:a], q.is[a+1:]...)
@@ -120,22 +110,47 @@ func (q *Queue) Reorder(a, b int) error {
 func (q *Queue) Copy() []interface{} {
 	q.mu.RLock()
 	defer q.mu.RUnlock()
-	x := make([]interface{}, q.Length())
+	x := make([]interface{}, len(q.is))
 	copy(x, q.is)
 	return x
 }
 
 // Remove removes the item at index i in the queue
 func (q *Queue) Remove(i int) error {
-	q.mu.RLock()
+	q.mu.Lock()
+	defer q.mu.Unlock()
 	if i < 0 || i > len(q.is)-1 {
-		q.mu.RUnlock()
 		return ErrOutOfBounds
 	}
-	q.mu.RUnlock()
 
+	q.is = append(q.is[:i], q.is[i+1:]...)
+	return nil
+}
+
+// Insert puts the element at index i in the queue, i can be the length of the queue to add it to the back
+func (q *Queue) Insert(i int, e interface{}) error {
 	q.mu.Lock()
 	defer q.mu.Unlock()
-	q.is = append(q.is[:i], q.is[i+1:]...)
+	if i < 0 || i > len(q.is) {
+		return ErrOutOfBounds
+	}
+
+	q.is = append(q.is[:i], append([]interface{}{e}, q.is[i:]...)...)
 	return nil
 }
+
+// Clear removes all elements from the queue
+func (q *Queue) Clear() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.is = make([]interface{}, 0)
+}
+
+// Shuffle puts the elements of the queue in a random order
+func (q *Queue) Shuffle() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	rand.Shuffle(len(q.is), func(i, j int) {
+		q.is[i], q.is[j] = q.is[j], q.is[i]
+	})
+}
@@ -2,6 +2,7 @@ package queue_test
 
 import (
 	"reflect"
+	"sort"
 	"testing"
 
 	"github.com/fvdveen/fuzzy/internal/queue"
@@ -50,3 +51,34 @@ func TestRemove(t *testing.T) {
 		}
 	}
 }
+
+func TestInsert(t *testing.T) {
+	for _, test := range insertTests {
+		q := queue.New()
+		q.PushBack(test.is...)
+		for _, f := range test.as {
+			err := f(q)
+			if err != nil {
+				t.Error(err)
+			}
+		}
+
+		if res := q.Copy(); !reflect.DeepEqual(res, test.res) {
+			t.Errorf("expected: %v got: %v", test.res, res)
+		}
+	}
+}
+
+func TestShuffle(t *testing.T) {
+	q := queue.New()
+	q.PushBack(1, 2, 3, 4, 5, 6, 7, 8, 9, 10)
+	q.Shuffle()
+
+	res := q.Copy()
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].(int) < res[j].(int)
+	})
+	if exp := []interface{}{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}; !reflect.DeepEqual(res, exp) {
+		t.Errorf("expected the shuffled queue to hold: %v got: %v", exp, res)
+	}
+}
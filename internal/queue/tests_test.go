@@ -141,3 +141,62 @@ var removeTests = []struct {
 		},
 	},
 }
+
+var insertTests = []struct {
+	is  []interface{}
+	as  []func(q *queue.Queue) error
+	res []interface{}
+}{
+	{
+		is: []interface{}{
+			1, 2, 3,
+		},
+		as: []func(q *queue.Queue) error{
+			func(q *queue.Queue) error {
+				if err := q.Insert(0, 0); err != nil {
+					return err
+				}
+				if err := q.Insert(2, 5); err != nil {
+					return err
+				}
+				return q.Insert(5, 4)
+			},
+		},
+		res: []interface{}{
+			0, 1, 5, 2, 3, 4,
+		},
+	},
+	{
+		is: []interface{}{
+			1, 2, 3,
+		},
+		as: []func(q *queue.Queue) error{
+			func(q *queue.Queue) error {
+				if err := q.Insert(-1, 0); err != queue.ErrOutOfBounds {
+					return fmt.Errorf("expected: %v got: %v", queue.ErrOutOfBounds, err)
+				}
+				if err := q.Insert(4, 0); err != queue.ErrOutOfBounds {
+					return fmt.Errorf("expected: %v got: %v", queue.ErrOutOfBounds, err)
+				}
+				return nil
+			},
+		},
+		res: []interface{}{
+			1, 2, 3,
+		},
+	},
+	{
+		is: []interface{}{
+			1, 2, 3,
+		},
+		as: []func(q *queue.Queue) error{
+			func(q *queue.Queue) error {
+				q.Clear()
+				return q.Insert(0, 4)
+			},
+		},
+		res: []interface{}{
+			4,
+		},
+	},
+}
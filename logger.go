@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 )
 
 const (
@@ -40,6 +41,42 @@ func LogLevelFrom(lvl string) LogLevel {
 	}
 }
 
+// String returns the name of the log level
+func (l LogLevel) String() string {
+	switch l {
+	case LogDebug:
+		return "debug"
+	case LogInfo:
+		return "info"
+	case LogWarn:
+		return "warn"
+	case LogError:
+		return "error"
+	case LogFatal:
+		return "fatal"
+	case LogPanic:
+		return "panic"
+	default:
+		return fmt.Sprintf("LogLevel(%d)", int(l))
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (l LogLevel) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+// unlike LogLevelFrom it fails on unrecognized levels
+func (l *LogLevel) UnmarshalText(b []byte) error {
+	lvl := LogLevelFrom(strings.ToLower(string(b)))
+	if lvl == LogDebug && strings.ToLower(string(b)) != "debug" {
+		return fmt.Errorf("%v: %s", ErrUnknownLogLevel, b)
+	}
+	*l = lvl
+	return nil
+}
+
 // LoggerGenerator creates a Logger
 type LoggerGenerator func(LogLevel) Logger
 
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fvdveen/fuzzy"
+)
+
+// RecoverEvents turns panics in event handlers into errors
+func RecoverEvents() fuzzy.EventMiddleware {
+	return func(next fuzzy.EventHandler) fuzzy.EventHandler {
+		return fuzzy.EventHandlerFunc(func(b *fuzzy.Bot, e interface{}) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = fmt.Errorf("handler panicked: %v", r)
+				}
+			}()
+			return next.HandleEvent(b, e)
+		})
+	}
+}
+
+// LogEvents logs every handled event and how long the handler took
+func LogEvents() fuzzy.EventMiddleware {
+	return func(next fuzzy.EventHandler) fuzzy.EventHandler {
+		return fuzzy.EventHandlerFunc(func(b *fuzzy.Bot, e interface{}) error {
+			start := time.Now()
+			err := next.HandleEvent(b, e)
+			b.Generator().Logger(b.Config().LogLevel).WithFields(map[string]interface{}{
+				"event":    fmt.Sprintf("%T", e),
+				"duration": time.Since(start),
+			}).Debugf("Handled event")
+			return err
+		})
+	}
+}
@@ -0,0 +1,326 @@
+package fuzzy
+
+import "fmt"
+
+// moduleStatePrefix prefixes module names in the CommandStateStore
+const moduleStatePrefix = "module:"
+
+// Module bundles commands, event handlers and middleware that are loaded together
+type Module interface {
+	Name() string
+	Commands() []Command
+	// Handlers are subscribed to the event bus of the bot, see EventBus.Subscribe
+	Handlers() []interface{}
+	// Middleware is only used for the commands of the module
+	Middleware() []Middleware
+
+	// Init is called before the module is loaded
+	Init(*Bot) error
+	// Teardown is called after the module is unloaded
+	Teardown(*Bot) error
+}
+
+// SettingsModule is a Module which declares guild settings
+// the settings are registered when the module is loaded
+type SettingsModule interface {
+	Module
+	Settings() []Setting
+}
+
+// ReloadableModule is a Module which is notified when the config of the bot is reloaded
+type ReloadableModule interface {
+	Module
+	Reload(*Bot, *Config) error
+}
+
+// ModuleOption sets a part of a module created by NewModule
+type ModuleOption func(*module)
+
+// ModuleCommands adds commands to the module
+func ModuleCommands(cs ...Command) ModuleOption {
+	return func(m *module) {
+		m.commands = append(m.commands, cs...)
+	}
+}
+
+// ModuleHandlers adds event handlers to the module, they take the same forms as EventBus.Subscribe
+func ModuleHandlers(hs ...interface{}) ModuleOption {
+	return func(m *module) {
+		m.handlers = append(m.handlers, hs...)
+	}
+}
+
+// ModuleMiddleware adds middleware for the commands of the module
+func ModuleMiddleware(ms ...Middleware) ModuleOption {
+	return func(m *module) {
+		m.middleware = append(m.middleware, ms...)
+	}
+}
+
+// ModuleSettings adds guild settings to the module
+func ModuleSettings(ss ...Setting) ModuleOption {
+	return func(m *module) {
+		m.settings = append(m.settings, ss...)
+	}
+}
+
+// OnInit sets the function called when the module is loaded
+func OnInit(f func(*Bot) error) ModuleOption {
+	return func(m *module) {
+		m.init = f
+	}
+}
+
+// OnReload sets the function called when the config of the bot is reloaded
+func OnReload(f func(*Bot, *Config) error) ModuleOption {
+	return func(m *module) {
+		m.reload = f
+	}
+}
+
+// OnTeardown sets the function called when the module is unloaded
+func OnTeardown(f func(*Bot) error) ModuleOption {
+	return func(m *module) {
+		m.teardown = f
+	}
+}
+
+// module is a implementation of Module
+type module struct {
+	name       string
+	commands   []Command
+	handlers   []interface{}
+	middleware []Middleware
+	settings   []Setting
+	init       func(*Bot) error
+	teardown   func(*Bot) error
+	reload     func(*Bot, *Config) error
+}
+
+// NewModule creates a new Module
+func NewModule(name string, opts ...ModuleOption) Module {
+	m := &module{name: name}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+func (m *module) Name() string {
+	return m.name
+}
+
+func (m *module) Commands() []Command {
+	return m.commands
+}
+
+func (m *module) Handlers() []interface{} {
+	return m.handlers
+}
+
+func (m *module) Middleware() []Middleware {
+	return m.middleware
+}
+
+func (m *module) Settings() []Setting {
+	return m.settings
+}
+
+func (m *module) Init(b *Bot) error {
+	if m.init == nil {
+		return nil
+	}
+	return m.init(b)
+}
+
+func (m *module) Teardown(b *Bot) error {
+	if m.teardown == nil {
+		return nil
+	}
+	return m.teardown(b)
+}
+
+func (m *module) Reload(b *Bot, c *Config) error {
+	if m.reload == nil {
+		return nil
+	}
+	return m.reload(b, c)
+}
+
+// loadedModule is a module that is loaded in the bot
+type loadedModule struct {
+	module   Module
+	commands []string
+	settings []string
+	subs     []*Subscription
+}
+
+// moduleCommand is a command of a module
+// it runs the middleware of the module and is disabled when the module is
+type moduleCommand struct {
+	Command
+
+	module  string
+	handler CommandHandler
+}
+
+func (c *moduleCommand) Handle(ctx Context) {
+	c.handler.Handle(ctx)
+}
+
+func (c *moduleCommand) Details() CommandDetails {
+	d := Details(c.Command)
+	d.Checks = append([]Check{c.moduleEnabled}, d.Checks...)
+	return d
+}
+
+// moduleEnabled checks if the module of the command is enabled in the guild
+func (c *moduleCommand) moduleEnabled(ctx Context) error {
+	ok, err := ctx.Bot().ModuleEnabled(ctx.MessageEvent().GuildID, c.module)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrCommandDisabled
+	}
+	return nil
+}
+
+// LoadModule initializes the module and registers its commands and handlers
+func (b *Bot) LoadModule(m Module) error {
+	b.modulesMu.Lock()
+	if _, ok := b.modules[m.Name()]; ok {
+		b.modulesMu.Unlock()
+		return ErrModuleLoaded
+	}
+	// the name is reserved so Init and Teardown can be called without holding modulesMu
+	b.modules[m.Name()] = nil
+	b.modulesMu.Unlock()
+
+	lm, err := b.initModule(m)
+
+	b.modulesMu.Lock()
+	defer b.modulesMu.Unlock()
+	if err != nil {
+		delete(b.modules, m.Name())
+		return err
+	}
+	b.modules[m.Name()] = lm
+	return nil
+}
+
+// initModule initializes the module and registers its commands, settings and handlers
+// everything is undone and the module is torn down when one of them fails
+func (b *Bot) initModule(m Module) (*loadedModule, error) {
+	if err := m.Init(b); err != nil {
+		return nil, fmt.Errorf("could not initialize module %s: %v", m.Name(), err)
+	}
+
+	lm := &loadedModule{module: m}
+	chain := NewMiddlewareChain(m.Middleware()...)
+	var cs []Command
+	for _, c := range m.Commands() {
+		cs = append(cs, &moduleCommand{
+			Command: c,
+			module:  m.Name(),
+			handler: chain.Then(c),
+		})
+		lm.commands = append(lm.commands, c.Name())
+	}
+	if err := b.RegisterCommand(cs...); err != nil {
+		_ = m.Teardown(b)
+		return nil, fmt.Errorf("could not register commands of module %s: %v", m.Name(), err)
+	}
+
+	if sm, ok := m.(SettingsModule); ok {
+		if err := b.RegisterSetting(sm.Settings()...); err != nil {
+			_ = b.UnregisterCommand(lm.commands...)
+			_ = m.Teardown(b)
+			return nil, fmt.Errorf("could not register settings of module %s: %v", m.Name(), err)
+		}
+		for _, s := range sm.Settings() {
+			lm.settings = append(lm.settings, s.Name)
+		}
+	}
+
+	for _, h := range m.Handlers() {
+		sub, err := b.events.Subscribe(h)
+		if err != nil {
+			lm.remove(b)
+			_ = m.Teardown(b)
+			return nil, fmt.Errorf("could not subscribe handlers of module %s: %v", m.Name(), err)
+		}
+		lm.subs = append(lm.subs, sub)
+	}
+
+	return lm, nil
+}
+
+// remove unregisters the commands, settings and handlers of the module
+func (lm *loadedModule) remove(b *Bot) error {
+	for _, sub := range lm.subs {
+		sub.Unsubscribe()
+	}
+	b.UnregisterSetting(lm.settings...)
+	return b.UnregisterCommand(lm.commands...)
+}
+
+// UnloadModule removes the commands and handlers of the module and tears it down
+func (b *Bot) UnloadModule(name string) error {
+	b.modulesMu.Lock()
+	lm := b.modules[name]
+	if lm == nil {
+		b.modulesMu.Unlock()
+		return ErrUnknownModule
+	}
+	delete(b.modules, name)
+	b.modulesMu.Unlock()
+
+	if err := lm.remove(b); err != nil {
+		return fmt.Errorf("could not unregister commands of module %s: %v", name, err)
+	}
+
+	if err := lm.module.Teardown(b); err != nil {
+		return fmt.Errorf("could not tear down module %s: %v", name, err)
+	}
+	return nil
+}
+
+// Modules returns the names of the loaded modules
+func (b *Bot) Modules() []string {
+	b.modulesMu.RLock()
+	defer b.modulesMu.RUnlock()
+
+	ns := make([]string, 0, len(b.modules))
+	for n, lm := range b.modules {
+		// modules that are still loading are not listed
+		if lm != nil {
+			ns = append(ns, n)
+		}
+	}
+	return ns
+}
+
+// EnableModule enables the commands of the module in the guild
+func (b *Bot) EnableModule(guildID, name string) error {
+	return b.commandState.SetDisabled(guildID, moduleStatePrefix+name, false)
+}
+
+// DisableModule disables the commands of the module in the guild
+// the event handlers of the module are not disabled
+func (b *Bot) DisableModule(guildID, name string) error {
+	return b.commandState.SetDisabled(guildID, moduleStatePrefix+name, true)
+}
+
+// ModuleEnabled reports if the module is enabled in the guild
+func (b *Bot) ModuleEnabled(guildID, name string) (bool, error) {
+	if guildID == "" {
+		return true, nil
+	}
+
+	d, err := b.commandState.Disabled(guildID, moduleStatePrefix+name)
+	if err != nil {
+		return false, fmt.Errorf("could not get module state: %v", err)
+	}
+	return !d, nil
+}
@@ -0,0 +1,167 @@
+package fuzzy_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/fvdveen/fuzzy"
+)
+
+func TestRegisterCommandDuplicate(t *testing.T) {
+	com := func(name string, aliases ...string) fuzzy.Command {
+		return fuzzy.NewCommand(name, "", func(fuzzy.Context) {}, fuzzy.WithAliases(aliases...))
+	}
+
+	tests := []struct {
+		name string
+		cs   []fuzzy.Command
+	}{
+		{"registered", []fuzzy.Command{com("first"), com("ping")}},
+		{"alias", []fuzzy.Command{com("first"), com("second", "p")}},
+		{"same call", []fuzzy.Command{com("first"), com("second"), com("first")}},
+		{"alias in same call", []fuzzy.Command{com("first", "f"), com("second", "f")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := fuzzy.New(fuzzy.WithConfig(&fuzzy.Config{Token: "token", Prefix: "!"}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := b.RegisterCommand(com("ping", "p")); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := b.RegisterCommand(tt.cs...); err != fuzzy.ErrDuplicateCommand {
+				t.Fatalf("expected error %v got %v", fuzzy.ErrDuplicateCommand, err)
+			}
+			if cs := b.Commands(); len(cs) != 1 {
+				t.Fatalf("expected no commands to be registered got %d commands", len(cs))
+			}
+		})
+	}
+}
+
+func TestLoadModuleDuplicateCommand(t *testing.T) {
+	b, err := fuzzy.New(fuzzy.WithConfig(&fuzzy.Config{Token: "token", Prefix: "!", LogLevel: fuzzy.LogError}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.RegisterCommand(fuzzy.NewCommand("ping", "", func(fuzzy.Context) {})); err != nil {
+		t.Fatal(err)
+	}
+
+	torn := false
+	m := fuzzy.NewModule("games",
+		fuzzy.ModuleCommands(
+			fuzzy.NewCommand("dice", "", func(fuzzy.Context) {}),
+			fuzzy.NewCommand("ping", "", func(fuzzy.Context) {}),
+		),
+		fuzzy.OnTeardown(func(*fuzzy.Bot) error {
+			torn = true
+			return nil
+		}),
+	)
+	if err := b.LoadModule(m); err == nil || !strings.Contains(err.Error(), fuzzy.ErrDuplicateCommand.Error()) {
+		t.Fatalf("expected error %v got %v", fuzzy.ErrDuplicateCommand, err)
+	}
+	if !torn {
+		t.Fatal("expected the module to be torn down")
+	}
+	if _, err := b.Command("dice"); err != fuzzy.ErrUnknownCommand {
+		t.Fatalf("expected dice to not be registered got %v", err)
+	}
+	if len(b.Modules()) != 0 {
+		t.Fatalf("expected no modules got %q", b.Modules())
+	}
+
+	// the module can be loaded once the name is free
+	if err := b.UnregisterCommand("ping"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.LoadModule(m); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// moduleEvent is published to the handlers of a module
+type moduleEvent struct {
+	name string
+}
+
+func TestModuleHandlers(t *testing.T) {
+	b, err := fuzzy.New(fuzzy.WithConfig(&fuzzy.Config{Token: "token", Prefix: "!", LogLevel: fuzzy.LogError}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	m := fuzzy.NewModule("log", fuzzy.ModuleHandlers(func(b *fuzzy.Bot, e *moduleEvent) {
+		got = append(got, e.name)
+	}))
+	if err := b.LoadModule(m); err != nil {
+		t.Fatal(err)
+	}
+	b.Inject(&moduleEvent{"first"})
+	if err := b.UnloadModule("log"); err != nil {
+		t.Fatal(err)
+	}
+	b.Inject(&moduleEvent{"second"})
+	if len(got) != 1 || got[0] != "first" {
+		t.Fatalf("expected only the event before unloading got %q", got)
+	}
+
+	// invalid handlers fail the load and tear the module down
+	torn := false
+	m = fuzzy.NewModule("invalid",
+		fuzzy.ModuleCommands(fuzzy.NewCommand("dice", "", func(fuzzy.Context) {})),
+		fuzzy.ModuleHandlers(func(*discordgo.Session, *discordgo.MessageCreate) {}),
+		fuzzy.OnTeardown(func(*fuzzy.Bot) error {
+			torn = true
+			return nil
+		}),
+	)
+	if err := b.LoadModule(m); err == nil || !strings.Contains(err.Error(), fuzzy.ErrInvalidEventHandler.Error()) {
+		t.Fatalf("expected error %v got %v", fuzzy.ErrInvalidEventHandler, err)
+	}
+	if !torn {
+		t.Fatal("expected the module to be torn down")
+	}
+	if _, err := b.Command("dice"); err != fuzzy.ErrUnknownCommand {
+		t.Fatalf("expected dice to not be registered got %v", err)
+	}
+}
+
+func TestModuleInitLoadsModule(t *testing.T) {
+	b, err := fuzzy.New(fuzzy.WithConfig(&fuzzy.Config{Token: "token", Prefix: "!", LogLevel: fuzzy.LogError}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Init and Teardown can use the modules of the bot
+	dep := fuzzy.NewModule("dep")
+	m := fuzzy.NewModule("main",
+		fuzzy.OnInit(func(b *fuzzy.Bot) error {
+			if err := b.LoadModule(fuzzy.NewModule("main")); err != fuzzy.ErrModuleLoaded {
+				t.Errorf("expected error %v got %v", fuzzy.ErrModuleLoaded, err)
+			}
+			return b.LoadModule(dep)
+		}),
+		fuzzy.OnTeardown(func(b *fuzzy.Bot) error {
+			return b.UnloadModule("dep")
+		}),
+	)
+	if err := b.LoadModule(m); err != nil {
+		t.Fatal(err)
+	}
+	if ms := b.Modules(); len(ms) != 2 {
+		t.Fatalf("expected both modules to be loaded got %q", ms)
+	}
+	if err := b.UnloadModule("main"); err != nil {
+		t.Fatal(err)
+	}
+	if ms := b.Modules(); len(ms) != 0 {
+		t.Fatalf("expected no modules got %q", ms)
+	}
+}
@@ -6,21 +6,28 @@ type OptionFunc func(*Bot)
 // WithConfig sets the bot's config
 func WithConfig(c *Config) OptionFunc {
 	return func(b *Bot) {
-		b.conf = c
+		b.conf.Store(c)
 	}
 }
 
 // WithToken sets the bot's token
 func WithToken(t string) OptionFunc {
 	return func(b *Bot) {
-		b.conf.Token = t
+		b.Config().Token = t
 	}
 }
 
 // WithPrefix sets the bots prefix
 func WithPrefix(p string) OptionFunc {
 	return func(b *Bot) {
-		b.conf.Prefix = p
+		b.Config().Prefix = p
+	}
+}
+
+// WithMaxMessageChunks sets the maximum amount of messages a long message is split into
+func WithMaxMessageChunks(n int) OptionFunc {
+	return func(b *Bot) {
+		b.Config().MaxMessageChunks = n
 	}
 }
 
@@ -30,3 +37,24 @@ func WithGenerator(g *Generator) OptionFunc {
 		b.generator = g
 	}
 }
+
+// WithTheme sets the colors used for the bot's embeds
+func WithTheme(t Theme) OptionFunc {
+	return func(b *Bot) {
+		b.theme = t
+	}
+}
+
+// WithStore sets the store used by the bot and its commands
+func WithStore(s Store) OptionFunc {
+	return func(b *Bot) {
+		b.store = s
+	}
+}
+
+// WithCommandStateStore sets the store that keeps which commands are disabled
+func WithCommandStateStore(s CommandStateStore) OptionFunc {
+	return func(b *Bot) {
+		b.commandState = s
+	}
+}
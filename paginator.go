@@ -0,0 +1,228 @@
+package fuzzy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// DefaultPaginatorTimeout is the time a paginator listens for reactions when no timeout is set
+	DefaultPaginatorTimeout = 5 * time.Minute
+
+	// maxPageLength is the maximum length of the text on a page created by PaginateLines
+	maxPageLength = 2000
+
+	// maxPageNumberLength is the room the page number may take up in the footer of an embed
+	maxPageNumberLength = len(" | Page 9999/9999")
+)
+
+// Navigation reactions used by the Paginator
+// they are fully qualified emoji, reactions are matched with or without the variation selector
+const (
+	PageFirst    = "⏮\ufe0f"
+	PagePrevious = "◀\ufe0f"
+	PageNext     = "▶\ufe0f"
+	PageLast     = "⏭\ufe0f"
+	PageStop     = "⏹\ufe0f"
+)
+
+// Page is a single page of a Paginator
+type Page struct {
+	Content string
+	Embed   *discordgo.MessageEmbed
+}
+
+// Paginator sends a message with multiple pages which the author of the command
+// can navigate through with reactions
+type Paginator struct {
+	Pages []Page
+
+	// Timeout is the time without reactions after which the controls are removed
+	Timeout time.Duration
+}
+
+// NewPaginator creates a paginator for the pages
+func NewPaginator(pages ...Page) *Paginator {
+	return &Paginator{
+		Pages:   pages,
+		Timeout: DefaultPaginatorTimeout,
+	}
+}
+
+// PaginateLines divides the lines over embed pages with the given title and description
+// each page holds at most perPage lines
+func PaginateLines(title, description string, lines []string, perPage int) []Page {
+	if perPage <= 0 {
+		perPage = len(lines)
+	}
+
+	var (
+		pages []Page
+		page  []string
+		l     = len(description)
+	)
+	flush := func() {
+		desc := strings.Join(page, "\n")
+		if description != "" {
+			desc = strings.TrimSpace(description + "\n\n" + desc)
+		}
+		pages = append(pages, Page{Embed: NewEmbed().SetTitle(title).SetDescription(desc).Build()})
+		page = nil
+		l = len(description)
+	}
+
+	for _, line := range lines {
+		if len(page) > 0 && (len(page) >= perPage || l+len(line)+1 > maxPageLength) {
+			flush()
+		}
+		page = append(page, line)
+		l += len(line) + 1
+	}
+	if len(page) > 0 || len(pages) == 0 {
+		flush()
+	}
+
+	return pages
+}
+
+// Send sends the first page and lets the author of the command navigate the pages
+// the reactions are handled in the background until the paginator is stopped or times out, use Run to wait for that
+func (p *Paginator) Send(ctx Context) error {
+	msg, err := p.send(ctx)
+	if err != nil || msg == nil {
+		return err
+	}
+
+	// the navigation outlives the command, it is counted as a running command until it is done
+	b := ctx.Bot()
+	b.activity.add(1)
+	go func() {
+		defer b.activity.add(-1)
+		if err := p.navigate(context.WithValue(context.Background(), commandKey{}, true), ctx, msg); err != nil {
+			ctx.Logger().Errorf("Could not navigate pages: %v", err)
+		}
+	}()
+	return nil
+}
+
+// Run sends the first page and lets the author of the command navigate the pages
+// it blocks until the paginator is stopped or times out
+func (p *Paginator) Run(ctx Context) error {
+	msg, err := p.send(ctx)
+	if err != nil || msg == nil {
+		return err
+	}
+	return p.navigate(ctx, ctx, msg)
+}
+
+// send sends the first page and adds the controls when there are more pages
+// it returns nil when there is nothing to navigate
+func (p *Paginator) send(ctx Context) (*discordgo.Message, error) {
+	if len(p.Pages) == 0 {
+		return nil, nil
+	}
+
+	msg, err := ctx.SendComplex(p.message(0))
+	if err != nil {
+		return nil, fmt.Errorf("could not send page: %v", err)
+	}
+	if len(p.Pages) == 1 {
+		return nil, nil
+	}
+
+	for _, c := range []string{PageFirst, PagePrevious, PageNext, PageLast, PageStop} {
+		if err := ctx.Transport().MessageReactionAdd(ctx.MessageEvent().ChannelID, msg.ID, c); err != nil {
+			return nil, fmt.Errorf("could not add page controls: %v", err)
+		}
+	}
+	return msg, nil
+}
+
+// navigate changes the page of msg on the reactions of the author of the command until c is done
+func (p *Paginator) navigate(c context.Context, ctx Context, msg *discordgo.Message) error {
+	s := ctx.Transport()
+	chanID := ctx.MessageEvent().ChannelID
+
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = DefaultPaginatorTimeout
+	}
+
+	cur := 0
+	for {
+		wc, cancel := context.WithTimeout(c, timeout)
+		r, err := ctx.Bot().WaitForReaction(wc, msg.ID, ctx.MessageEvent().Author.ID)
+		cancel()
+		if err != nil {
+			return s.MessageReactionsRemoveAll(chanID, msg.ID)
+		}
+
+		_ = s.MessageReactionRemove(chanID, msg.ID, r.Emoji.APIName(), r.UserID)
+
+		next := cur
+		switch emojiName(r.Emoji.Name) {
+		case emojiName(PageFirst):
+			next = 0
+		case emojiName(PagePrevious):
+			if cur > 0 {
+				next = cur - 1
+			}
+		case emojiName(PageNext):
+			if cur < len(p.Pages)-1 {
+				next = cur + 1
+			}
+		case emojiName(PageLast):
+			next = len(p.Pages) - 1
+		case emojiName(PageStop):
+			return s.MessageReactionsRemoveAll(chanID, msg.ID)
+		}
+		if next == cur {
+			continue
+		}
+		cur = next
+
+		ms := p.message(cur)
+		e := discordgo.NewMessageEdit(chanID, msg.ID).SetContent(ms.Content)
+		e.Embed = ms.Embed
+		if _, err := s.ChannelMessageEditComplex(e); err != nil {
+			return fmt.Errorf("could not change page: %v", err)
+		}
+	}
+}
+
+// emojiName strips the variation selector from an emoji so its text and emoji presentation are equal
+func emojiName(e string) string {
+	return strings.Replace(e, "\ufe0f", "", -1)
+}
+
+// message creates the message for page i including the page number
+func (p *Paginator) message(i int) *discordgo.MessageSend {
+	pg := p.Pages[i]
+	ms := &discordgo.MessageSend{Content: pg.Content}
+	if len(p.Pages) == 1 {
+		ms.Embed = pg.Embed
+		return ms
+	}
+
+	num := fmt.Sprintf("Page %d/%d", i+1, len(p.Pages))
+	if pg.Embed == nil {
+		ms.Content = fmt.Sprintf("%s\n\n%s", pg.Content, num)
+		return ms
+	}
+
+	e := *pg.Embed
+	if e.Footer == nil {
+		e.Footer = &discordgo.MessageEmbedFooter{Text: num}
+	} else {
+		// the footer of the page is kept and the page number is added to it
+		f := *e.Footer
+		f.Text = fmt.Sprintf("%s | %s", f.Text, num)
+		e.Footer = &f
+	}
+	ms.Embed = &e
+	return ms
+}
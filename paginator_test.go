@@ -0,0 +1,113 @@
+package fuzzy_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fvdveen/fuzzy"
+	"github.com/fvdveen/fuzzy/fuzzytest"
+)
+
+func TestPaginateLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		lines   []string
+		perPage int
+		pages   []string
+	}{
+		{"no lines", nil, 2, []string{"desc"}},
+		{"per page", []string{"a", "b", "c"}, 2, []string{"desc\n\na\nb", "desc\n\nc"}},
+		{"one page", []string{"a", "b", "c"}, 0, []string{"desc\n\na\nb\nc"}},
+		{"length", []string{strings.Repeat("a", 1500), strings.Repeat("b", 1500)}, 10, []string{
+			"desc\n\n" + strings.Repeat("a", 1500), "desc\n\n" + strings.Repeat("b", 1500),
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var descs []string
+			for _, p := range fuzzy.PaginateLines("title", "desc", tt.lines, tt.perPage) {
+				if p.Embed.Title != "title" {
+					t.Fatalf("expected title title got %s", p.Embed.Title)
+				}
+				descs = append(descs, p.Embed.Description)
+			}
+			if !reflect.DeepEqual(descs, tt.pages) {
+				t.Fatalf("expected pages %q got %q", tt.pages, descs)
+			}
+		})
+	}
+}
+
+func TestPaginator(t *testing.T) {
+	h, err := fuzzytest.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	if err := h.Bot.RegisterCommand(
+		fuzzy.NewCommand("pages", "", func(ctx fuzzy.Context) {
+			p := fuzzy.NewPaginator(fuzzy.Page{Content: "one"}, fuzzy.Page{Content: "two"}, fuzzy.Page{Content: "three"})
+			p.Timeout = time.Minute
+			done <- p.Send(ctx)
+		}),
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := h.SendIdle(fuzzytest.DefaultChannelID, fuzzytest.DefaultUserID, "!pages"); err != nil {
+		t.Fatal(err)
+	}
+	// Send returns while the pages can still be navigated
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	m := h.LastMessage()
+	if m == nil || m.Content != "one\n\nPage 1/3" {
+		t.Fatalf("expected the first page got %v", m)
+	}
+	var controls []string
+	for _, a := range h.ActionsOf(fuzzytest.ActionReact) {
+		controls = append(controls, a.Emoji)
+	}
+	if exp := []string{fuzzy.PageFirst, fuzzy.PagePrevious, fuzzy.PageNext, fuzzy.PageLast, fuzzy.PageStop}; !reflect.DeepEqual(controls, exp) {
+		t.Fatalf("expected controls %q got %q", exp, controls)
+	}
+
+	for _, tt := range []struct {
+		emoji string
+		page  string
+	}{
+		{fuzzy.PageNext, "two\n\nPage 2/3"},
+		// discord may leave out the variation selector
+		{"▶", "three\n\nPage 3/3"},
+		{fuzzy.PageFirst, "one\n\nPage 1/3"},
+		{"⏭", "three\n\nPage 3/3"},
+		{fuzzy.PagePrevious, "two\n\nPage 2/3"},
+	} {
+		if err := h.ReactIdle(fuzzytest.DefaultChannelID, m.ID, fuzzytest.DefaultUserID, tt.emoji); err != nil {
+			t.Fatal(err)
+		}
+		if cur := h.CurrentMessage(m.ID); cur.Content != tt.page {
+			t.Fatalf("expected page %q after %s got %q", tt.page, tt.emoji, cur.Content)
+		}
+	}
+
+	// reactions of other users are ignored
+	h.Reset()
+	if err := h.ReactIdle(fuzzytest.DefaultChannelID, m.ID, "4001", fuzzy.PageNext); err != nil {
+		t.Fatal(err)
+	}
+	if as := h.ActionsOf(fuzzytest.ActionEdit); len(as) != 0 {
+		t.Fatalf("expected no edits got %v", as)
+	}
+
+	if err := h.ReactIdle(fuzzytest.DefaultChannelID, m.ID, fuzzytest.DefaultUserID, fuzzy.PageStop); err != nil {
+		t.Fatal(err)
+	}
+	if as := h.ActionsOf(fuzzytest.ActionClearReactions); len(as) != 1 {
+		t.Fatalf("expected the controls to be removed got %v", as)
+	}
+}
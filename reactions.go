@@ -0,0 +1,81 @@
+package fuzzy
+
+import (
+	"context"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// reactionWaiter is waiting for the next reaction of a user on a message
+type reactionWaiter struct {
+	msgID  string
+	userID string
+	c      chan *discordgo.MessageReactionAdd
+	// tracked is set when a command handler is waiting
+	tracked bool
+}
+
+// WaitForReaction waits for the next reaction added by the user to the given message
+func (b *Bot) WaitForReaction(ctx context.Context, msgID, userID string) (*discordgo.MessageReactionAdd, error) {
+	w := &reactionWaiter{
+		msgID:   msgID,
+		userID:  userID,
+		c:       make(chan *discordgo.MessageReactionAdd, 1),
+		tracked: tracked(ctx),
+	}
+
+	b.reactionsMu.Lock()
+	b.reactions = append(b.reactions, w)
+	if w.tracked {
+		b.activity.wait()
+	}
+	b.reactionsMu.Unlock()
+
+	select {
+	case r := <-w.c:
+		return r, nil
+	case <-ctx.Done():
+		if !b.removeReactionWaiter(w) {
+			// it was delivered in the meantime
+			return <-w.c, nil
+		}
+		if w.tracked {
+			b.activity.add(1)
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// deliverReaction gives the reaction to the first waiter waiting for it
+// it returns false if no one was waiting for the reaction
+func (b *Bot) deliverReaction(r *discordgo.MessageReactionAdd) bool {
+	b.reactionsMu.Lock()
+	defer b.reactionsMu.Unlock()
+
+	for i, w := range b.reactions {
+		if w.msgID == r.MessageID && w.userID == r.UserID {
+			b.reactions = append(b.reactions[:i], b.reactions[i+1:]...)
+			if w.tracked {
+				b.activity.add(1)
+			}
+			w.c <- r
+			return true
+		}
+	}
+
+	return false
+}
+
+// removeReactionWaiter removes the waiter and reports whether it was still waiting
+func (b *Bot) removeReactionWaiter(w *reactionWaiter) bool {
+	b.reactionsMu.Lock()
+	defer b.reactionsMu.Unlock()
+
+	for i, w2 := range b.reactions {
+		if w2 == w {
+			b.reactions = append(b.reactions[:i], b.reactions[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
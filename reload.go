@@ -0,0 +1,181 @@
+package fuzzy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	// ConfigPollInterval is how often a watched config file is checked for changes
+	ConfigPollInterval = 2 * time.Second
+
+	// configJobName is the name of the scheduled job that watches the config file
+	configJobName = "config"
+)
+
+// Reload replaces the config of the bot, changes are logged and published as ConfigReloaded
+// the token can not change while the bot runs so a changed token is ignored until the bot is restarted
+func (b *Bot) Reload(c *Config) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	// reloads run one at a time so the modules see the configs in the order they were stored
+	b.reloadMu.Lock()
+	defer b.reloadMu.Unlock()
+
+	old := b.Config()
+	log := b.Generator().Logger(old.LogLevel)
+
+	nc := *c
+	if nc.Token != old.Token || nc.TokenFile != old.TokenFile {
+		log.Warnf("The token changed, restart the bot to use it")
+		nc.Token, nc.TokenFile = old.Token, old.TokenFile
+	}
+
+	changes := diffConfig(old, &nc)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	b.conf.Store(&nc)
+	log = b.Generator().Logger(nc.LogLevel)
+	for _, ch := range changes {
+		log.Infof("Config changed: %s", ch)
+	}
+
+	b.events.Publish(&ConfigReloaded{Old: old, New: &nc, Changes: changes})
+
+	// the hooks are called without holding modulesMu so they can load and unload modules
+	b.modulesMu.RLock()
+	names := make([]string, 0, len(b.modules))
+	rms := make(map[string]ReloadableModule, len(b.modules))
+	for name, lm := range b.modules {
+		if lm == nil {
+			continue
+		}
+		if rm, ok := lm.module.(ReloadableModule); ok {
+			names = append(names, name)
+			rms[name] = rm
+		}
+	}
+	b.modulesMu.RUnlock()
+
+	sort.Strings(names)
+	for _, name := range names {
+		if err := rms[name].Reload(b, &nc); err != nil {
+			log.Errorf("Could not reload module %s: %v", name, err)
+		}
+	}
+
+	return nil
+}
+
+// WatchConfig reloads the config from the same sources as LoadConfig when the config file changes
+// or the process receives SIGHUP, the file is checked every ConfigPollInterval while the bot is open
+func (b *Bot) WatchConfig(opts ...ConfigOption) error {
+	l := &configLoader{}
+	for _, opt := range opts {
+		opt(l)
+	}
+	env, flags, err := l.overrides()
+	if err != nil {
+		return err
+	}
+	path, _ := l.path(env, flags)
+	if path == "" {
+		return ErrNoConfigFile
+	}
+
+	modTime := func() time.Time {
+		fi, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}
+		}
+		return fi.ModTime()
+	}
+	last := modTime()
+
+	s, err := Every(ConfigPollInterval, 0)
+	if err != nil {
+		return err
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	_, err = b.Schedule(configJobName, s, func(ctx context.Context) error {
+		select {
+		case <-hup:
+		default:
+			t := modTime()
+			if t.Equal(last) {
+				return nil
+			}
+			last = t
+		}
+
+		c, err := l.load()
+		if err != nil {
+			return fmt.Errorf("could not reload config: %v", err)
+		}
+		return b.Reload(c)
+	}, WithOverlapPolicy(OverlapSkip))
+	if err != nil {
+		signal.Stop(hup)
+	}
+	return err
+}
+
+// diffConfig describes the changes from old to new
+func diffConfig(old, new *Config) []string {
+	var changes []string
+
+	ov, nv := reflect.ValueOf(old).Elem(), reflect.ValueOf(new).Elem()
+	t := ov.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		a, b := ov.Field(i).Interface(), nv.Field(i).Interface()
+		if reflect.DeepEqual(a, b) {
+			continue
+		}
+
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		switch f.Name {
+		case "Token", "TokenFile":
+			changes = append(changes, name+" changed")
+		case "Modules":
+			changes = append(changes, diffModules(old.Modules, new.Modules)...)
+		default:
+			changes = append(changes, fmt.Sprintf("%s: %v -> %v", name, a, b))
+		}
+	}
+
+	return changes
+}
+
+// diffModules describes which module sections changed
+func diffModules(old, new map[string]json.RawMessage) []string {
+	var changes []string
+	for n, raw := range new {
+		if !bytes.Equal(old[n], raw) {
+			changes = append(changes, fmt.Sprintf("modules.%s changed", n))
+		}
+	}
+	for n := range old {
+		if _, ok := new[n]; !ok {
+			changes = append(changes, fmt.Sprintf("modules.%s removed", n))
+		}
+	}
+	sort.Strings(changes)
+	return changes
+}
@@ -0,0 +1,124 @@
+package fuzzy_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fvdveen/fuzzy"
+)
+
+func TestReload(t *testing.T) {
+	b, err := fuzzy.New(fuzzy.WithConfig(&fuzzy.Config{
+		Token:    "token",
+		Prefix:   "!",
+		LogLevel: fuzzy.LogError,
+		Modules:  map[string]json.RawMessage{"music": json.RawMessage(`{"volume":50}`)},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var reloaded *fuzzy.Config
+	if err := b.LoadModule(fuzzy.NewModule("music", fuzzy.OnReload(func(_ *fuzzy.Bot, c *fuzzy.Config) error {
+		reloaded = c
+		return nil
+	}))); err != nil {
+		t.Fatal(err)
+	}
+
+	var ev *fuzzy.ConfigReloaded
+	if _, err := b.Events().Subscribe(func(_ *fuzzy.Bot, e *fuzzy.ConfigReloaded) {
+		ev = e
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := b.Reload(&fuzzy.Config{
+		Token:    "new token",
+		Prefix:   "?",
+		LogLevel: fuzzy.LogError,
+		Modules:  map[string]json.RawMessage{"music": json.RawMessage(`{"volume":80}`)},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	c := b.Config()
+	if c.Prefix != "?" || c.Token != "token" {
+		t.Fatalf("expected the new prefix and the old token got %q and %q", c.Prefix, c.Token)
+	}
+	if reloaded != c {
+		t.Fatal("the module was not reloaded with the new config")
+	}
+	if ev == nil {
+		t.Fatal("no ConfigReloaded event was published")
+	}
+	exp := []string{"prefix: ! -> ?", "modules.music changed"}
+	if !reflect.DeepEqual(ev.Changes, exp) {
+		t.Fatalf("expected changes %q got %q", exp, ev.Changes)
+	}
+
+	if err := b.Reload(&fuzzy.Config{Token: "token"}); err == nil {
+		t.Fatal("reloaded an invalid config")
+	}
+}
+
+func TestReloadModules(t *testing.T) {
+	b, err := fuzzy.New(fuzzy.WithConfig(&fuzzy.Config{Token: "token", Prefix: "!", LogLevel: fuzzy.LogError}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var running, overlaps int32
+	if err := b.LoadModule(fuzzy.NewModule("cleanup", fuzzy.OnReload(func(b *fuzzy.Bot, _ *fuzzy.Config) error {
+		if atomic.AddInt32(&running, 1) > 1 {
+			atomic.AddInt32(&overlaps, 1)
+		}
+		defer atomic.AddInt32(&running, -1)
+		time.Sleep(time.Millisecond)
+
+		// the hooks can change the modules
+		if err := b.UnloadModule("old"); err != nil && err != fuzzy.ErrUnknownModule {
+			return err
+		}
+		return nil
+	}))); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.LoadModule(fuzzy.NewModule("old")); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				if err := b.Reload(&fuzzy.Config{Token: "token", Prefix: fmt.Sprintf("%d!", i), LogLevel: fuzzy.LogError}); err != nil {
+					t.Error(err)
+				}
+			}(i)
+		}
+		wg.Wait()
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("reloading did not finish")
+	}
+
+	if n := atomic.LoadInt32(&overlaps); n != 0 {
+		t.Fatalf("expected reloads to run one at a time got %d overlaps", n)
+	}
+	if ms := b.Modules(); !reflect.DeepEqual(ms, []string{"cleanup"}) {
+		t.Fatalf("expected the old module to be unloaded got %q", ms)
+	}
+}
@@ -0,0 +1,17 @@
+package reminders
+
+import "errors"
+
+var (
+	// ErrNoTime is used when a reminder does not start with a time
+	ErrNoTime = errors.New("could not find when to remind you")
+
+	// ErrInvalidTime is used when a time of day is not valid
+	ErrInvalidTime = errors.New("invalid time of day")
+
+	// ErrInPast is used when a reminder would be in the past
+	ErrInPast = errors.New("that time has already passed")
+
+	// ErrUnknownReminder is used when there is no reminder with the given ID
+	ErrUnknownReminder = errors.New("unknown reminder")
+)
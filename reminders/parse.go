@@ -0,0 +1,210 @@
+package reminders
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultHour is the hour used when a day is given without a time
+const defaultHour = 9
+
+var (
+	durationRe = regexp.MustCompile(`^(\d+)(w|d|h|m|s)`)
+	clockRe    = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?\s*(am|pm)?$`)
+
+	units = map[string]time.Duration{
+		"w": 7 * 24 * time.Hour, "week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour,
+		"d": 24 * time.Hour, "day": 24 * time.Hour, "days": 24 * time.Hour,
+		"h": time.Hour, "hr": time.Hour, "hrs": time.Hour, "hour": time.Hour, "hours": time.Hour,
+		"m": time.Minute, "min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+		"s": time.Second, "sec": time.Second, "secs": time.Second, "second": time.Second, "seconds": time.Second,
+	}
+
+	weekdays = map[string]time.Weekday{
+		"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+		"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+	}
+)
+
+// ParseWhen parses the time at the start of s relative to now and returns it with the rest of s
+// it understands durations like "in 2h30m" or "in 2 hours and 30 minutes",
+// days like "tomorrow 9am", "friday at 17:30" or "today noon" and times like "at 5pm"
+// days and times are in the location of now
+func ParseWhen(s string, now time.Time) (time.Time, string, error) {
+	ts := strings.Fields(s)
+	if len(ts) == 0 {
+		return time.Time{}, "", ErrNoTime
+	}
+
+	first := strings.ToLower(ts[0])
+	switch {
+	case first == "in":
+		d, n := parseDuration(ts[1:])
+		if n == 0 {
+			return time.Time{}, "", fmt.Errorf("%v: %q is not a duration", ErrNoTime, strings.Join(ts[1:], " "))
+		}
+		return now.Add(d), strings.Join(ts[1+n:], " "), nil
+	case first == "today" || first == "tomorrow" || isWeekday(first):
+		day := dayFrom(first, now)
+		rest := ts[1:]
+		if len(rest) > 0 && strings.EqualFold(rest[0], "at") {
+			rest = rest[1:]
+		}
+
+		h, m, n, err := parseClock(rest)
+		if err != nil {
+			return time.Time{}, "", err
+		}
+		if n == 0 {
+			h, m = defaultHour, 0
+		}
+
+		t := time.Date(day.Year(), day.Month(), day.Day(), h, m, 0, 0, now.Location())
+		if !t.After(now) {
+			if first == "today" || first == "tomorrow" {
+				return time.Time{}, "", ErrInPast
+			}
+			t = t.AddDate(0, 0, 7)
+		}
+		return t, strings.Join(rest[n:], " "), nil
+	case first == "at":
+		h, m, n, err := parseClock(ts[1:])
+		if err != nil {
+			return time.Time{}, "", err
+		}
+		if n == 0 {
+			return time.Time{}, "", fmt.Errorf("%v: missing time after at", ErrNoTime)
+		}
+
+		t := time.Date(now.Year(), now.Month(), now.Day(), h, m, 0, 0, now.Location())
+		if !t.After(now) {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, strings.Join(ts[1+n:], " "), nil
+	}
+
+	if d, n := parseDuration(ts); n > 0 {
+		return now.Add(d), strings.Join(ts[n:], " "), nil
+	}
+	return time.Time{}, "", ErrNoTime
+}
+
+// parseDuration parses the duration at the start of the tokens and returns how many tokens it used
+func parseDuration(ts []string) (time.Duration, int) {
+	var (
+		total time.Duration
+		used  int
+	)
+	for i := 0; i < len(ts); i++ {
+		t := strings.ToLower(strings.TrimSuffix(ts[i], ","))
+
+		if d, ok := parseCompact(t); ok {
+			total += d
+			used = i + 1
+			continue
+		}
+
+		if n, err := strconv.Atoi(t); err == nil && i+1 < len(ts) {
+			u, ok := units[strings.ToLower(strings.TrimSuffix(ts[i+1], ","))]
+			if !ok {
+				break
+			}
+			total += time.Duration(n) * u
+			i++
+			used = i + 1
+			continue
+		}
+
+		if (t == "and" || t == "") && used > 0 {
+			continue
+		}
+		break
+	}
+	return total, used
+}
+
+// parseCompact parses durations like "2h30m" or "1d"
+func parseCompact(s string) (time.Duration, bool) {
+	if s == "" {
+		return 0, false
+	}
+
+	var total time.Duration
+	for s != "" {
+		m := durationRe.FindStringSubmatch(s)
+		if m == nil {
+			return 0, false
+		}
+		n, _ := strconv.Atoi(m[1])
+		total += time.Duration(n) * units[m[2]]
+		s = s[len(m[0]):]
+	}
+	return total, true
+}
+
+// parseClock parses a time of day at the start of the tokens and returns how many tokens it used
+// it returns 0 tokens when there is no time of day
+func parseClock(ts []string) (int, int, int, error) {
+	if len(ts) == 0 {
+		return 0, 0, 0, nil
+	}
+
+	switch strings.ToLower(ts[0]) {
+	case "noon":
+		return 12, 0, 1, nil
+	case "midnight":
+		return 0, 0, 1, nil
+	}
+
+	s, n := strings.ToLower(ts[0]), 1
+	if len(ts) > 1 && (strings.EqualFold(ts[1], "am") || strings.EqualFold(ts[1], "pm")) {
+		s, n = s+strings.ToLower(ts[1]), 2
+	}
+
+	m := clockRe.FindStringSubmatch(s)
+	if m == nil || (m[2] == "" && m[3] == "") {
+		return 0, 0, 0, nil
+	}
+
+	h, _ := strconv.Atoi(m[1])
+	min := 0
+	if m[2] != "" {
+		min, _ = strconv.Atoi(m[2])
+	}
+	switch m[3] {
+	case "am", "pm":
+		if h < 1 || h > 12 {
+			return 0, 0, 0, fmt.Errorf("%v: %s", ErrInvalidTime, s)
+		}
+		h %= 12
+		if m[3] == "pm" {
+			h += 12
+		}
+	}
+	if h > 23 || min > 59 {
+		return 0, 0, 0, fmt.Errorf("%v: %s", ErrInvalidTime, s)
+	}
+
+	return h, min, n, nil
+}
+
+func isWeekday(s string) bool {
+	_, ok := weekdays[s]
+	return ok
+}
+
+// dayFrom returns the day the word refers to
+func dayFrom(s string, now time.Time) time.Time {
+	switch s {
+	case "today":
+		return now
+	case "tomorrow":
+		return now.AddDate(0, 0, 1)
+	}
+
+	diff := (int(weekdays[s]) - int(now.Weekday()) + 7) % 7
+	return now.AddDate(0, 0, diff)
+}
@@ -0,0 +1,55 @@
+package reminders_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fvdveen/fuzzy/reminders"
+)
+
+func TestParseWhen(t *testing.T) {
+	// a wednesday
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, test := range whenTests {
+		due, rest, err := reminders.ParseWhen(test.in, now)
+		if err != nil {
+			t.Errorf("%q: %v", test.in, err)
+			continue
+		}
+		if !due.Equal(test.due) {
+			t.Errorf("%q: expected: %s got: %s", test.in, test.due, due)
+		}
+		if rest != test.rest {
+			t.Errorf("%q: expected message: %q got: %q", test.in, test.rest, rest)
+		}
+	}
+}
+
+func TestParseWhenErrors(t *testing.T) {
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, in := range []string{"", "check the oven", "in a while", "today 9am", "at 25:00", "tomorrow 13pm"} {
+		if _, _, err := reminders.ParseWhen(in, now); err == nil {
+			t.Errorf("%q: expected error", in)
+		}
+	}
+}
+
+var whenTests = []struct {
+	in   string
+	due  time.Time
+	rest string
+}{
+	{"in 2h30m check the oven", time.Date(2020, 1, 1, 14, 30, 0, 0, time.UTC), "check the oven"},
+	{"in 2 hours and 30 minutes check", time.Date(2020, 1, 1, 14, 30, 0, 0, time.UTC), "check"},
+	{"in 1d", time.Date(2020, 1, 2, 12, 0, 0, 0, time.UTC), ""},
+	{"45m tea", time.Date(2020, 1, 1, 12, 45, 0, 0, time.UTC), "tea"},
+	{"tomorrow 9am call", time.Date(2020, 1, 2, 9, 0, 0, 0, time.UTC), "call"},
+	{"tomorrow at 9:30 pm call", time.Date(2020, 1, 2, 21, 30, 0, 0, time.UTC), "call"},
+	{"tomorrow standup", time.Date(2020, 1, 2, 9, 0, 0, 0, time.UTC), "standup"},
+	{"today 17:00 go home", time.Date(2020, 1, 1, 17, 0, 0, 0, time.UTC), "go home"},
+	{"at 11am meeting", time.Date(2020, 1, 2, 11, 0, 0, 0, time.UTC), "meeting"},
+	{"friday noon lunch", time.Date(2020, 1, 3, 12, 0, 0, 0, time.UTC), "lunch"},
+	{"wednesday 9am", time.Date(2020, 1, 8, 9, 0, 0, 0, time.UTC), ""},
+}
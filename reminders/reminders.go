@@ -0,0 +1,308 @@
+// Package reminders is a fuzzy module that lets users set reminders
+//
+//	remind in 2h30m check the oven
+//	remind dm tomorrow 9am call the dentist
+//	remind list
+//	remind cancel <id>
+//	remind timezone Europe/Amsterdam
+package reminders
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/fvdveen/fuzzy"
+)
+
+const (
+	// jobName is the name of the scheduled job that sends due reminders
+	jobName = "reminders"
+
+	// checkInterval is how often due reminders are sent
+	checkInterval = 5 * time.Second
+
+	// maxSendAttempts is how often sending a reminder is tried before it is removed
+	maxSendAttempts = 5
+)
+
+// reminders sends reminders set by users
+type reminders struct {
+	store Store
+}
+
+// New creates the reminders module
+func New(s Store) fuzzy.Module {
+	r := &reminders{store: s}
+
+	return fuzzy.NewModule("reminders",
+		fuzzy.ModuleCommands(r.command()),
+		fuzzy.OnInit(func(b *fuzzy.Bot) error {
+			s, err := fuzzy.Every(checkInterval, 0)
+			if err != nil {
+				return err
+			}
+			_, err = b.Schedule(jobName, s, func(ctx context.Context) error {
+				return r.sendDue(ctx, b)
+			})
+			return err
+		}),
+		fuzzy.OnTeardown(func(b *fuzzy.Bot) error {
+			return b.Unschedule(jobName)
+		}),
+	)
+}
+
+func (r *reminders) command() fuzzy.Command {
+	return fuzzy.NewCommand("remind", "Reminds you of something later", func(ctx fuzzy.Context) {
+		args := strings.Fields(ctx.Message())
+		if len(args) == 0 {
+			_, _ = ctx.SendError(fmt.Errorf("usage: %sremind %s", ctx.Bot().Prefix(ctx.MessageEvent().GuildID), fuzzy.Details(ctx.Command()).Usage))
+			return
+		}
+
+		var err error
+		switch strings.ToLower(args[0]) {
+		case "list":
+			err = r.list(ctx)
+		case "cancel":
+			err = r.cancel(ctx, args[1:])
+		case "timezone":
+			err = r.setTimezone(ctx, args[1:])
+		default:
+			err = r.add(ctx)
+		}
+		if err != nil {
+			_, _ = ctx.SendError(err)
+		}
+	},
+		fuzzy.WithCategory("Utility"),
+		fuzzy.WithUsage("[dm] <when> <message> | list | cancel <id> | timezone <zone>"),
+		fuzzy.WithParameters(
+			fuzzy.Parameter{Name: "dm", Description: "Send the reminder as a direct message", Optional: true},
+			fuzzy.Parameter{Name: "when", Description: `When to remind you, like "in 2h30m", "tomorrow 9am" or "friday at 17:00"`},
+			fuzzy.Parameter{Name: "message", Description: "What to remind you of", Optional: true},
+		),
+		fuzzy.WithExamples(
+			"remind in 2h30m check the oven",
+			"remind dm tomorrow 9am call the dentist",
+			"remind list",
+			"remind timezone Europe/Amsterdam",
+		),
+	)
+}
+
+// add adds the reminder in the message of the context
+func (r *reminders) add(ctx fuzzy.Context) error {
+	msg := ctx.Message()
+	dm := false
+	if f := strings.Fields(msg); len(f) > 0 && strings.EqualFold(f[0], "dm") {
+		dm = true
+		msg = strings.TrimSpace(msg[len(f[0]):])
+	}
+
+	m := ctx.MessageEvent()
+	loc, err := r.location(m.Author.ID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().In(loc)
+	due, text, err := ParseWhen(msg, now)
+	if err != nil {
+		return err
+	}
+	if text == "" {
+		text = "Reminder"
+	}
+
+	id, err := newID()
+	if err != nil {
+		return fmt.Errorf("could not create reminder: %v", err)
+	}
+	rem := &Reminder{
+		ID:        id,
+		UserID:    m.Author.ID,
+		GuildID:   m.GuildID,
+		ChannelID: m.ChannelID,
+		Message:   text,
+		Due:       due,
+		Created:   now,
+		DM:        dm,
+	}
+	if err := r.store.Add(rem); err != nil {
+		ctx.Logger().Errorf("Could not store reminder: %v", err)
+		return fmt.Errorf("could not save the reminder")
+	}
+
+	_, err = ctx.SendMessage(fmt.Sprintf("I will remind you %s (`%s`)", formatDue(due, now), id))
+	return err
+}
+
+// list sends the reminders of the author
+func (r *reminders) list(ctx fuzzy.Context) error {
+	rs, err := r.store.List(ctx.MessageEvent().Author.ID)
+	if err != nil {
+		ctx.Logger().Errorf("Could not list reminders: %v", err)
+		return fmt.Errorf("could not get your reminders")
+	}
+	if len(rs) == 0 {
+		_, err := ctx.SendMessage("You have no reminders")
+		return err
+	}
+
+	loc, err := r.location(ctx.MessageEvent().Author.ID)
+	if err != nil {
+		return err
+	}
+	now := time.Now().In(loc)
+
+	var lines []string
+	for _, rem := range rs {
+		lines = append(lines, fmt.Sprintf("`%s` %s: %s", rem.ID, formatDue(rem.Due.In(loc), now), rem.Message))
+	}
+
+	pages := fuzzy.PaginateLines("Your reminders", "", lines, 10)
+	for _, p := range pages {
+		p.Embed.Color = ctx.Bot().Theme().Primary
+	}
+	return fuzzy.NewPaginator(pages...).Send(ctx)
+}
+
+// cancel removes a reminder of the author
+func (r *reminders) cancel(ctx fuzzy.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("which reminder should be cancelled? use %sremind list to see their IDs", ctx.Bot().Prefix(ctx.MessageEvent().GuildID))
+	}
+
+	rs, err := r.store.List(ctx.MessageEvent().Author.ID)
+	if err != nil {
+		ctx.Logger().Errorf("Could not list reminders: %v", err)
+		return fmt.Errorf("could not get your reminders")
+	}
+	for _, rem := range rs {
+		if rem.ID != args[0] {
+			continue
+		}
+		if err := r.store.Remove(rem.ID); err != nil {
+			return err
+		}
+		_, err := ctx.SendMessage(fmt.Sprintf("Cancelled reminder `%s`", rem.ID))
+		return err
+	}
+
+	return ErrUnknownReminder
+}
+
+// setTimezone sets the timezone used for the author's reminders
+func (r *reminders) setTimezone(ctx fuzzy.Context, args []string) error {
+	if len(args) == 0 {
+		tz, err := r.store.Timezone(ctx.MessageEvent().Author.ID)
+		if err != nil {
+			return err
+		}
+		if tz == "" {
+			tz = "UTC"
+		}
+		_, err = ctx.SendMessage(fmt.Sprintf("Your timezone is %s", tz))
+		return err
+	}
+
+	if _, err := time.LoadLocation(args[0]); err != nil {
+		return fmt.Errorf("unknown timezone %s, use a name like Europe/Amsterdam", args[0])
+	}
+	if err := r.store.SetTimezone(ctx.MessageEvent().Author.ID, args[0]); err != nil {
+		ctx.Logger().Errorf("Could not store timezone: %v", err)
+		return fmt.Errorf("could not save your timezone")
+	}
+
+	_, err := ctx.SendMessage(fmt.Sprintf("Your timezone is now %s", args[0]))
+	return err
+}
+
+// location returns the timezone of the user
+func (r *reminders) location(userID string) (*time.Location, error) {
+	tz, err := r.store.Timezone(userID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get your timezone: %v", err)
+	}
+	if tz == "" {
+		return time.UTC, nil
+	}
+
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return time.UTC, nil
+	}
+	return loc, nil
+}
+
+// sendDue sends all reminders that are due and removes them
+// reminders that could not be sent are kept and tried again up to maxSendAttempts times
+func (r *reminders) sendDue(ctx context.Context, b *fuzzy.Bot) error {
+	rs, err := r.store.Due(time.Now())
+	if err != nil {
+		return fmt.Errorf("could not get due reminders: %v", err)
+	}
+
+	s := b.Transport()
+	log := b.Generator().Logger(b.Config().LogLevel)
+	for _, rem := range rs {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		chanID := rem.ChannelID
+		if rem.DM {
+			c, err := s.UserChannelCreate(rem.UserID)
+			if err != nil {
+				log.Errorf("Could not create dm channel for reminder %s: %v", rem.ID, err)
+			} else {
+				chanID = c.ID
+			}
+		}
+
+		// only the user of the reminder is mentioned, the message is written by the user and may mention anyone
+		ms := &discordgo.MessageSend{
+			Content:         fmt.Sprintf("<@%s> ⏰ %s", rem.UserID, rem.Message),
+			AllowedMentions: &discordgo.MessageAllowedMentions{Users: []string{rem.UserID}},
+		}
+		if _, err := s.ChannelMessageSendComplex(chanID, ms); err != nil {
+			rem.Attempts++
+			if rem.Attempts < maxSendAttempts {
+				log.Warnf("Could not send reminder %s, trying again: %v", rem.ID, err)
+				if err := r.store.Add(rem); err != nil {
+					log.Errorf("Could not update reminder %s: %v", rem.ID, err)
+				}
+				continue
+			}
+			log.Errorf("Could not send reminder %s after %d attempts, removing it: %v", rem.ID, rem.Attempts, err)
+		}
+		if err := r.store.Remove(rem.ID); err != nil {
+			log.Errorf("Could not remove reminder %s: %v", rem.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// formatDue describes when a reminder is due
+func formatDue(due, now time.Time) string {
+	d := due.Sub(now).Round(time.Minute)
+	if d < time.Minute {
+		return "in less than a minute"
+	}
+	return fmt.Sprintf("in %s (%s)", strings.TrimSuffix(d.String(), "0s"), due.Format("Mon 2 Jan 15:04 MST"))
+}
+
+func newID() (string, error) {
+	b := make([]byte, 3)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
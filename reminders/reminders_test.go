@@ -0,0 +1,100 @@
+package reminders
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/fvdveen/fuzzy"
+)
+
+// sendTransport records the sent messages and fails them while fail is set
+type sendTransport struct {
+	fuzzy.Transport
+	fail bool
+	sent []*discordgo.MessageSend
+}
+
+func (t *sendTransport) ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend) (*discordgo.Message, error) {
+	if t.fail {
+		return nil, errors.New("could not send")
+	}
+	t.sent = append(t.sent, data)
+	return &discordgo.Message{ChannelID: channelID, Content: data.Content}, nil
+}
+
+func TestSendDue(t *testing.T) {
+	st := &sendTransport{fail: true}
+	b, err := fuzzy.New(
+		fuzzy.WithConfig(&fuzzy.Config{Token: "token", Prefix: "!", LogLevel: fuzzy.LogError}),
+		func(b *fuzzy.Bot) {
+			b.Generator().SetTransportGenerator(func(s *discordgo.Session) fuzzy.Transport {
+				st.Transport = fuzzy.DefaultTransport(s)
+				return st
+			})
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := &reminders{store: NewMemoryStore()}
+	if err := r.store.Add(&Reminder{
+		ID:        "abc",
+		UserID:    "4000",
+		ChannelID: "3000",
+		Message:   "tell @everyone",
+		Due:       time.Now().Add(-time.Minute),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// failed reminders are kept until they are sent
+	for i := 1; i < maxSendAttempts; i++ {
+		if err := r.sendDue(context.Background(), b); err != nil {
+			t.Fatal(err)
+		}
+		rs, err := r.store.List("4000")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rs) != 1 || rs[0].Attempts != i {
+			t.Fatalf("expected the reminder to be kept after %d attempts got %v", i, rs)
+		}
+	}
+
+	st.fail = false
+	if err := r.sendDue(context.Background(), b); err != nil {
+		t.Fatal(err)
+	}
+	if len(st.sent) != 1 {
+		t.Fatalf("expected the reminder to be sent got %d messages", len(st.sent))
+	}
+	ms := st.sent[0]
+	if ms.Content != "<@4000> ⏰ tell @everyone" {
+		t.Fatalf("unexpected reminder %q", ms.Content)
+	}
+	if ms.AllowedMentions == nil || !reflect.DeepEqual(ms.AllowedMentions.Users, []string{"4000"}) || len(ms.AllowedMentions.Parse) != 0 {
+		t.Fatalf("expected only the user to be mentioned got %+v", ms.AllowedMentions)
+	}
+	if rs, _ := r.store.List("4000"); len(rs) != 0 {
+		t.Fatalf("expected the reminder to be removed got %v", rs)
+	}
+
+	// reminders are given up on after maxSendAttempts
+	st.fail = true
+	if err := r.store.Add(&Reminder{ID: "def", UserID: "4000", ChannelID: "3000", Due: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < maxSendAttempts; i++ {
+		if err := r.sendDue(context.Background(), b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if rs, _ := r.store.List("4000"); len(rs) != 0 {
+		t.Fatalf("expected the reminder to be removed after %d attempts got %v", maxSendAttempts, rs)
+	}
+}
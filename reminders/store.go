@@ -0,0 +1,200 @@
+package reminders
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fvdveen/fuzzy"
+)
+
+// Reminder is a message that is sent to a user at a later time
+type Reminder struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	GuildID   string    `json:"guild_id,omitempty"`
+	ChannelID string    `json:"channel_id"`
+	Message   string    `json:"message"`
+	Due       time.Time `json:"due"`
+	Created   time.Time `json:"created"`
+	// DM sends the reminder as a direct message instead of in the channel
+	DM bool `json:"dm,omitempty"`
+	// Attempts is the amount of times sending the reminder failed
+	Attempts int `json:"attempts,omitempty"`
+}
+
+// Store keeps the reminders and the timezones of users
+type Store interface {
+	Add(*Reminder) error
+	Remove(id string) error
+	// List returns the reminders of the user ordered by when they are due
+	List(userID string) ([]*Reminder, error)
+	// Due returns all reminders that are due at t
+	Due(t time.Time) ([]*Reminder, error)
+
+	// Timezone returns the IANA timezone of the user, "" when it is not set
+	Timezone(userID string) (string, error)
+	SetTimezone(userID, tz string) error
+}
+
+type memoryStore struct {
+	mu        sync.RWMutex
+	reminders map[string]*Reminder
+	timezones map[string]string
+}
+
+// NewMemoryStore creates a Store which keeps everything in memory
+func NewMemoryStore() Store {
+	return &memoryStore{
+		reminders: make(map[string]*Reminder),
+		timezones: make(map[string]string),
+	}
+}
+
+func (s *memoryStore) Add(r *Reminder) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reminders[r.ID] = r
+	return nil
+}
+
+func (s *memoryStore) Remove(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.reminders[id]; !ok {
+		return ErrUnknownReminder
+	}
+	delete(s.reminders, id)
+	return nil
+}
+
+func (s *memoryStore) List(userID string) ([]*Reminder, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var rs []*Reminder
+	for _, r := range s.reminders {
+		if r.UserID == userID {
+			rs = append(rs, r)
+		}
+	}
+	sortByDue(rs)
+	return rs, nil
+}
+
+func (s *memoryStore) Due(t time.Time) ([]*Reminder, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var rs []*Reminder
+	for _, r := range s.reminders {
+		if !r.Due.After(t) {
+			rs = append(rs, r)
+		}
+	}
+	sortByDue(rs)
+	return rs, nil
+}
+
+func (s *memoryStore) Timezone(userID string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.timezones[userID], nil
+}
+
+func (s *memoryStore) SetTimezone(userID, tz string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.timezones[userID] = tz
+	return nil
+}
+
+const (
+	reminderPrefix = "reminder/"
+	timezonePrefix = "timezone/"
+)
+
+type kvStore struct {
+	s fuzzy.Store
+}
+
+// NewKVStore creates a Store which keeps everything in a fuzzy.Store
+// like fuzzy.Namespace(b.Store(), "reminders")
+func NewKVStore(s fuzzy.Store) Store {
+	return &kvStore{s: s}
+}
+
+func (s *kvStore) Add(r *Reminder) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return s.s.Set(reminderPrefix+r.ID, b, 0)
+}
+
+func (s *kvStore) Remove(id string) error {
+	if _, err := s.s.Get(reminderPrefix + id); err == fuzzy.ErrNotFound {
+		return ErrUnknownReminder
+	} else if err != nil {
+		return err
+	}
+	return s.s.Delete(reminderPrefix + id)
+}
+
+func (s *kvStore) List(userID string) ([]*Reminder, error) {
+	return s.filter(func(r *Reminder) bool {
+		return r.UserID == userID
+	})
+}
+
+func (s *kvStore) Due(t time.Time) ([]*Reminder, error) {
+	return s.filter(func(r *Reminder) bool {
+		return !r.Due.After(t)
+	})
+}
+
+// filter returns the reminders for which f returns true ordered by when they are due
+func (s *kvStore) filter(f func(*Reminder) bool) ([]*Reminder, error) {
+	es, err := s.s.List(reminderPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var rs []*Reminder
+	for _, e := range es {
+		r := new(Reminder)
+		if err := json.Unmarshal(e.Value, r); err != nil {
+			return nil, err
+		}
+		if f(r) {
+			rs = append(rs, r)
+		}
+	}
+	sortByDue(rs)
+	return rs, nil
+}
+
+func (s *kvStore) Timezone(userID string) (string, error) {
+	b, err := s.s.Get(timezonePrefix + userID)
+	if err == fuzzy.ErrNotFound {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func (s *kvStore) SetTimezone(userID, tz string) error {
+	return s.s.Set(timezonePrefix+userID, []byte(tz), 0)
+}
+
+func sortByDue(rs []*Reminder) {
+	sort.Slice(rs, func(i, j int) bool {
+		return rs[i].Due.Before(rs[j].Due)
+	})
+}
@@ -0,0 +1,82 @@
+package fuzzy
+
+import (
+	"context"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// replyWaiter is waiting for the next message of a user in a channel
+type replyWaiter struct {
+	chanID string
+	userID string
+	c      chan *discordgo.MessageCreate
+	// tracked is set when a command handler is waiting
+	tracked bool
+}
+
+// WaitForReply waits for the next message sent by the user in the given channel
+// the message is not handled as a command
+func (b *Bot) WaitForReply(ctx context.Context, chanID, userID string) (*discordgo.MessageCreate, error) {
+	w := &replyWaiter{
+		chanID:  chanID,
+		userID:  userID,
+		c:       make(chan *discordgo.MessageCreate, 1),
+		tracked: tracked(ctx),
+	}
+
+	b.repliesMu.Lock()
+	b.replies = append(b.replies, w)
+	if w.tracked {
+		b.activity.wait()
+	}
+	b.repliesMu.Unlock()
+
+	select {
+	case m := <-w.c:
+		return m, nil
+	case <-ctx.Done():
+		if !b.removeReplyWaiter(w) {
+			// it was delivered in the meantime
+			return <-w.c, nil
+		}
+		if w.tracked {
+			b.activity.add(1)
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// deliverReply gives the message to the first waiter waiting for it
+// it returns false if no one was waiting for the message
+func (b *Bot) deliverReply(m *discordgo.MessageCreate) bool {
+	b.repliesMu.Lock()
+	defer b.repliesMu.Unlock()
+
+	for i, w := range b.replies {
+		if w.chanID == m.ChannelID && w.userID == m.Author.ID {
+			b.replies = append(b.replies[:i], b.replies[i+1:]...)
+			if w.tracked {
+				b.activity.add(1)
+			}
+			w.c <- m
+			return true
+		}
+	}
+
+	return false
+}
+
+// removeReplyWaiter removes the waiter and reports whether it was still waiting
+func (b *Bot) removeReplyWaiter(w *replyWaiter) bool {
+	b.repliesMu.Lock()
+	defer b.repliesMu.Unlock()
+
+	for i, w2 := range b.replies {
+		if w2 == w {
+			b.replies = append(b.replies[:i], b.replies[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
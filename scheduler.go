@@ -0,0 +1,288 @@
+package fuzzy
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fvdveen/fuzzy/internal/cron"
+)
+
+// Schedule decides when a job runs
+type Schedule interface {
+	// Next returns the first time after t the job should run
+	// the zero time means the job never runs again
+	Next(t time.Time) time.Time
+}
+
+// Cron creates a schedule from a standard 5 field cron spec like "0 9 * * mon-fri"
+func Cron(spec string) (Schedule, error) {
+	s, err := cron.Parse(spec)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+type intervalSchedule struct {
+	interval time.Duration
+	jitter   time.Duration
+}
+
+// Every creates a schedule that runs every interval plus a random duration of up to jitter
+// the interval has to be positive
+func Every(interval, jitter time.Duration) (Schedule, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("%v: %s", ErrInvalidInterval, interval)
+	}
+	return intervalSchedule{
+		interval: interval,
+		jitter:   jitter,
+	}, nil
+}
+
+func (s intervalSchedule) Next(t time.Time) time.Time {
+	next := t.Add(s.interval)
+	if s.jitter > 0 {
+		next = next.Add(time.Duration(rand.Int63n(int64(s.jitter))))
+	}
+	return next
+}
+
+// OverlapPolicy decides what happens when a job is due while its previous run is not done
+type OverlapPolicy int
+
+const (
+	// OverlapSkip skips the run
+	OverlapSkip OverlapPolicy = iota
+	// OverlapAllow runs the job next to the previous run
+	OverlapAllow
+	// OverlapWait runs the job once the previous run is done
+	OverlapWait
+)
+
+// JobFunc is the function run by a job
+// the context is cancelled when the job times out or the bot is closed
+type JobFunc func(context.Context) error
+
+// JobOption sets an option of a Job
+type JobOption func(*Job)
+
+// WithJobTimeout sets the maximum duration of a single run of the job
+func WithJobTimeout(d time.Duration) JobOption {
+	return func(j *Job) {
+		j.timeout = d
+	}
+}
+
+// WithOverlapPolicy sets what happens when the job is due while it is still running
+func WithOverlapPolicy(p OverlapPolicy) JobOption {
+	return func(j *Job) {
+		j.overlap = p
+	}
+}
+
+// Job is a function that is run by the bot on a schedule
+type Job struct {
+	name     string
+	schedule Schedule
+	f        JobFunc
+	timeout  time.Duration
+	overlap  OverlapPolicy
+	log      Logger
+
+	running int32
+	runMu   sync.Mutex
+	wg      sync.WaitGroup
+	cancel  context.CancelFunc
+	stopped chan struct{}
+}
+
+// Name returns the name of the job
+func (j *Job) Name() string {
+	return j.name
+}
+
+// start runs the job on its schedule until the context is done
+func (j *Job) start(ctx context.Context) {
+	ctx, j.cancel = context.WithCancel(ctx)
+	j.stopped = make(chan struct{})
+
+	go func() {
+		defer close(j.stopped)
+		for {
+			next := j.schedule.Next(time.Now())
+			if next.IsZero() {
+				return
+			}
+
+			t := time.NewTimer(time.Until(next))
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				j.wg.Wait()
+				return
+			case <-t.C:
+			}
+
+			j.trigger(ctx)
+		}
+	}()
+}
+
+// stop stops scheduling the job and waits for running runs to finish
+func (j *Job) stop() {
+	if j.cancel == nil {
+		return
+	}
+	j.cancel()
+	<-j.stopped
+}
+
+// trigger starts a run of the job according to its overlap policy
+func (j *Job) trigger(ctx context.Context) {
+	switch j.overlap {
+	case OverlapSkip:
+		if !atomic.CompareAndSwapInt32(&j.running, 0, 1) {
+			j.log.Warnf("Skipping job %s, the previous run is not done", j.name)
+			return
+		}
+		j.wg.Add(1)
+		go func() {
+			defer j.wg.Done()
+			defer atomic.StoreInt32(&j.running, 0)
+			j.run(ctx)
+		}()
+	case OverlapWait:
+		j.wg.Add(1)
+		go func() {
+			defer j.wg.Done()
+			j.runMu.Lock()
+			defer j.runMu.Unlock()
+			j.run(ctx)
+		}()
+	default:
+		j.wg.Add(1)
+		go func() {
+			defer j.wg.Done()
+			j.run(ctx)
+		}()
+	}
+}
+
+// run runs the job once, panics and errors are logged
+func (j *Job) run(ctx context.Context) {
+	if ctx.Err() != nil {
+		return
+	}
+	if j.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, j.timeout)
+		defer cancel()
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			j.log.Errorf("Job %s panicked: %v", j.name, r)
+		}
+	}()
+
+	if err := j.f(ctx); err != nil {
+		j.log.Errorf("Job %s failed: %v", j.name, err)
+	}
+}
+
+// scheduler runs the bot's jobs while the bot is open
+type scheduler struct {
+	mu      sync.Mutex
+	jobs    map[string]*Job
+	ctx     context.Context
+	cancel  context.CancelFunc
+	running bool
+}
+
+func newScheduler() *scheduler {
+	return &scheduler{
+		jobs: make(map[string]*Job),
+	}
+}
+
+// Schedule adds a job that runs f on the schedule while the bot is open
+func (b *Bot) Schedule(name string, s Schedule, f JobFunc, opts ...JobOption) (*Job, error) {
+	j := &Job{
+		name:     name,
+		schedule: s,
+		f:        f,
+		log:      b.Generator().Logger(b.Config().LogLevel).WithField("job", name),
+	}
+	for _, opt := range opts {
+		opt(j)
+	}
+
+	sc := b.scheduler
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	if _, ok := sc.jobs[name]; ok {
+		return nil, fmt.Errorf("%v: %s", ErrDuplicateJob, name)
+	}
+	sc.jobs[name] = j
+	if sc.running {
+		j.start(sc.ctx)
+	}
+
+	return j, nil
+}
+
+// Unschedule stops the job and removes it from the bot
+func (b *Bot) Unschedule(name string) error {
+	sc := b.scheduler
+	sc.mu.Lock()
+	j, ok := sc.jobs[name]
+	delete(sc.jobs, name)
+	sc.mu.Unlock()
+
+	if !ok {
+		return ErrUnknownJob
+	}
+	j.stop()
+	return nil
+}
+
+// start starts all jobs
+func (sc *scheduler) start() {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	if sc.running {
+		return
+	}
+	sc.ctx, sc.cancel = context.WithCancel(context.Background())
+	sc.running = true
+	for _, j := range sc.jobs {
+		j.start(sc.ctx)
+	}
+}
+
+// stop stops all jobs and waits for them to finish
+func (sc *scheduler) stop() {
+	sc.mu.Lock()
+	if !sc.running {
+		sc.mu.Unlock()
+		return
+	}
+	sc.running = false
+	sc.cancel()
+	jobs := make([]*Job, 0, len(sc.jobs))
+	for _, j := range sc.jobs {
+		jobs = append(jobs, j)
+	}
+	sc.mu.Unlock()
+
+	for _, j := range jobs {
+		j.stop()
+	}
+}
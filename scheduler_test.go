@@ -0,0 +1,40 @@
+package fuzzy_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fvdveen/fuzzy"
+)
+
+func TestEvery(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval time.Duration
+		err      bool
+	}{
+		{name: "positive", interval: time.Minute},
+		{name: "zero", err: true},
+		{name: "negative", interval: -time.Minute, err: true},
+	}
+
+	now := time.Now()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := fuzzy.Every(tt.interval, time.Second)
+			if tt.err {
+				if err == nil || !strings.Contains(err.Error(), fuzzy.ErrInvalidInterval.Error()) {
+					t.Fatalf("expected error %v got %v", fuzzy.ErrInvalidInterval, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if next := s.Next(now); next.Before(now.Add(tt.interval)) || !next.Before(now.Add(tt.interval+time.Second)) {
+				t.Fatalf("expected the next run within the jitter after %s got %s", tt.interval, next.Sub(now))
+			}
+		})
+	}
+}
@@ -0,0 +1,506 @@
+package fuzzy
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// names of the settings every bot has
+const (
+	PrefixSetting           = "prefix"
+	LocaleSetting           = "locale"
+	DJRoleSetting           = "dj_role"
+	DisabledCommandsSetting = "disabled_commands"
+)
+
+var (
+	channelMention = regexp.MustCompile(`^<#(\d+)>$`)
+	roleMention    = regexp.MustCompile(`^<@&(\d+)>$`)
+	snowflake      = regexp.MustCompile(`^\d+$`)
+	localeName     = regexp.MustCompile(`^[a-z]{2}(-[A-Z]{2})?$`)
+)
+
+// SettingType is the type of the value of a setting
+type SettingType int
+
+const (
+	// StringSettingType is a string
+	StringSettingType SettingType = iota
+	// IntSettingType is an int64
+	IntSettingType
+	// BoolSettingType is a bool
+	BoolSettingType
+	// ChannelSettingType is a channel ID
+	ChannelSettingType
+	// RoleSettingType is a role ID
+	RoleSettingType
+	// StringListSettingType is a []string
+	StringListSettingType
+)
+
+func (t SettingType) String() string {
+	switch t {
+	case IntSettingType:
+		return "number"
+	case BoolSettingType:
+		return "yes/no"
+	case ChannelSettingType:
+		return "channel"
+	case RoleSettingType:
+		return "role"
+	case StringListSettingType:
+		return "list"
+	default:
+		return "text"
+	}
+}
+
+// Setting is a value that can be changed per guild
+type Setting struct {
+	Name        string
+	Description string
+	Type        SettingType
+	// Default is the value of the setting when it is not set
+	Default interface{}
+	// Validate checks the value before it is set, nil allows all values of the type
+	Validate func(interface{}) error
+	// Permissions are required to change the setting, 0 means manage server
+	Permissions int64
+}
+
+// Parse parses the value of the setting from s
+func (s *Setting) Parse(str string) (interface{}, error) {
+	str = strings.TrimSpace(str)
+
+	switch s.Type {
+	case IntSettingType:
+		i, err := strconv.ParseInt(str, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %s is not a number", ErrInvalidSetting, str)
+		}
+		return i, nil
+	case BoolSettingType:
+		switch strings.ToLower(str) {
+		case "yes", "y", "true", "on", "1":
+			return true, nil
+		case "no", "n", "false", "off", "0":
+			return false, nil
+		}
+		return nil, fmt.Errorf("%v: use yes or no", ErrInvalidSetting)
+	case ChannelSettingType:
+		return parseID(str, channelMention, "channel")
+	case RoleSettingType:
+		return parseID(str, roleMention, "role")
+	case StringListSettingType:
+		return strings.FieldsFunc(str, func(r rune) bool {
+			return r == ',' || r == ' '
+		}), nil
+	default:
+		return str, nil
+	}
+}
+
+// Format formats a value of the setting for display
+func (s *Setting) Format(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "not set"
+	case string:
+		if v == "" {
+			return "not set"
+		}
+		switch s.Type {
+		case ChannelSettingType:
+			return "<#" + v + ">"
+		case RoleSettingType:
+			return "<@&" + v + ">"
+		}
+		return v
+	case bool:
+		if v {
+			return "yes"
+		}
+		return "no"
+	case []string:
+		if len(v) == 0 {
+			return "none"
+		}
+		return strings.Join(v, ", ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// decode decodes a stored value of the setting
+func (s *Setting) decode(b []byte) (interface{}, error) {
+	var err error
+	switch s.Type {
+	case IntSettingType:
+		var i int64
+		err = json.Unmarshal(b, &i)
+		return i, err
+	case BoolSettingType:
+		var v bool
+		err = json.Unmarshal(b, &v)
+		return v, err
+	case StringListSettingType:
+		var l []string
+		err = json.Unmarshal(b, &l)
+		return l, err
+	default:
+		var str string
+		err = json.Unmarshal(b, &str)
+		return str, err
+	}
+}
+
+func parseID(s string, mention *regexp.Regexp, what string) (string, error) {
+	if m := mention.FindStringSubmatch(s); m != nil {
+		return m[1], nil
+	}
+	if snowflake.MatchString(s) {
+		return s, nil
+	}
+	return "", fmt.Errorf("%v: %s is not a %s", ErrInvalidSetting, s, what)
+}
+
+// settings are the settings registered in the bot and a cache of their values
+type settings struct {
+	mu       sync.RWMutex
+	settings map[string]*Setting
+	cache    map[string]map[string]interface{}
+}
+
+func newSettings() *settings {
+	return &settings{
+		settings: make(map[string]*Setting),
+		cache:    make(map[string]map[string]interface{}),
+	}
+}
+
+// defaultSettings are the settings every bot has
+func (b *Bot) defaultSettings() []Setting {
+	return []Setting{
+		{
+			Name:        PrefixSetting,
+			Description: "The prefix of the commands",
+			Type:        StringSettingType,
+			Default:     "",
+			Validate: func(v interface{}) error {
+				if p := v.(string); p == "" || strings.ContainsAny(p, " \n\t") {
+					return fmt.Errorf("%v: the prefix can not be empty or contain spaces", ErrInvalidSetting)
+				}
+				return nil
+			},
+		},
+		{
+			Name:        LocaleSetting,
+			Description: "The language of the bot",
+			Type:        StringSettingType,
+			Default:     "en",
+			Validate: func(v interface{}) error {
+				if !localeName.MatchString(v.(string)) {
+					return fmt.Errorf("%v: use a locale like en or en-US", ErrInvalidSetting)
+				}
+				return nil
+			},
+		},
+		{
+			Name:        DJRoleSetting,
+			Description: "The role that may control music",
+			Type:        RoleSettingType,
+			Default:     "",
+		},
+		{
+			Name:        DisabledCommandsSetting,
+			Description: "Commands that can not be used in the server",
+			Type:        StringListSettingType,
+			Default:     []string{},
+			Validate: func(v interface{}) error {
+				for _, n := range v.([]string) {
+					if _, err := b.Command(n); err != nil {
+						return fmt.Errorf("%v: %s", err, n)
+					}
+				}
+				return nil
+			},
+		},
+	}
+}
+
+// RegisterSetting adds settings to the bot
+func (b *Bot) RegisterSetting(ss ...Setting) error {
+	b.settings.mu.Lock()
+	defer b.settings.mu.Unlock()
+
+	for i := range ss {
+		if _, ok := b.settings.settings[ss[i].Name]; ok {
+			return fmt.Errorf("%v: %s", ErrDuplicateSetting, ss[i].Name)
+		}
+	}
+	for i := range ss {
+		s := ss[i]
+		b.settings.settings[s.Name] = &s
+	}
+	return nil
+}
+
+// UnregisterSetting removes settings from the bot, their values are kept
+func (b *Bot) UnregisterSetting(names ...string) {
+	b.settings.mu.Lock()
+	defer b.settings.mu.Unlock()
+
+	for _, n := range names {
+		delete(b.settings.settings, n)
+	}
+}
+
+// Setting returns the registered setting with the name
+func (b *Bot) Setting(name string) (*Setting, error) {
+	b.settings.mu.RLock()
+	defer b.settings.mu.RUnlock()
+
+	s, ok := b.settings.settings[name]
+	if !ok {
+		return nil, fmt.Errorf("%v: %s", ErrUnknownSetting, name)
+	}
+	return s, nil
+}
+
+// Settings returns all registered settings ordered by name
+func (b *Bot) Settings() []*Setting {
+	b.settings.mu.RLock()
+	defer b.settings.mu.RUnlock()
+
+	ss := make([]*Setting, 0, len(b.settings.settings))
+	for _, s := range b.settings.settings {
+		ss = append(ss, s)
+	}
+	sort.Slice(ss, func(i, j int) bool {
+		return ss[i].Name < ss[j].Name
+	})
+	return ss
+}
+
+// settingsStore is the part of the bot's store with the settings of the guild
+func (b *Bot) settingsStore(guildID string) Store {
+	return Namespace(b.GuildStore(guildID), "settings")
+}
+
+// GuildSetting returns the value of the setting in the guild or its default when it is not set
+// values are cached so changes made to the store outside of the bot are not seen
+func (b *Bot) GuildSetting(guildID, name string) (interface{}, error) {
+	s, err := b.Setting(name)
+	if err != nil {
+		return nil, err
+	}
+	if guildID == "" {
+		return s.Default, nil
+	}
+
+	b.settings.mu.RLock()
+	v, ok := b.settings.cache[guildID][name]
+	b.settings.mu.RUnlock()
+	if ok {
+		return v, nil
+	}
+
+	raw, err := b.settingsStore(guildID).Get(name)
+	if err == ErrNotFound {
+		v = s.Default
+	} else if err != nil {
+		return nil, fmt.Errorf("could not get setting %s: %v", name, err)
+	} else if v, err = s.decode(raw); err != nil {
+		return nil, fmt.Errorf("could not decode setting %s: %v", name, err)
+	}
+
+	b.cacheSetting(guildID, name, v)
+	return v, nil
+}
+
+// SetGuildSetting validates the value and sets the setting in the guild
+func (b *Bot) SetGuildSetting(guildID, name string, v interface{}) error {
+	s, err := b.Setting(name)
+	if err != nil {
+		return err
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("could not encode setting %s: %v", name, err)
+	}
+	if v, err = s.decode(raw); err != nil {
+		return fmt.Errorf("%v: expected a %s", ErrInvalidSetting, s.Type)
+	}
+	if s.Validate != nil {
+		if err := s.Validate(v); err != nil {
+			return err
+		}
+	}
+
+	if err := b.settingsStore(guildID).Set(name, raw, 0); err != nil {
+		return fmt.Errorf("could not set setting %s: %v", name, err)
+	}
+
+	b.cacheSetting(guildID, name, v)
+	return nil
+}
+
+// ResetGuildSetting sets the setting in the guild back to its default
+func (b *Bot) ResetGuildSetting(guildID, name string) error {
+	s, err := b.Setting(name)
+	if err != nil {
+		return err
+	}
+	if err := b.settingsStore(guildID).Delete(name); err != nil {
+		return fmt.Errorf("could not reset setting %s: %v", name, err)
+	}
+
+	b.cacheSetting(guildID, name, s.Default)
+	return nil
+}
+
+func (b *Bot) cacheSetting(guildID, name string, v interface{}) {
+	b.settings.mu.Lock()
+	defer b.settings.mu.Unlock()
+
+	if b.settings.cache[guildID] == nil {
+		b.settings.cache[guildID] = make(map[string]interface{})
+	}
+	b.settings.cache[guildID][name] = v
+}
+
+// Prefix returns the prefix of the commands in the guild
+func (b *Bot) Prefix(guildID string) string {
+	v, err := b.GuildSetting(guildID, PrefixSetting)
+	if err != nil {
+		b.Generator().Logger(b.Config().LogLevel).Errorf("Could not get prefix: %v", err)
+	}
+	if p, ok := v.(string); ok && p != "" {
+		return p
+	}
+	return b.Config().Prefix
+}
+
+// guildCommandDisabled reports if the command is in the disabled commands setting of the guild
+func (b *Bot) guildCommandDisabled(guildID, name string) (bool, error) {
+	v, err := b.GuildSetting(guildID, DisabledCommandsSetting)
+	if err != nil {
+		return false, err
+	}
+	l, _ := v.([]string)
+	for _, n := range l {
+		if com, err := b.Command(n); err == nil && com.Name() == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// SettingsCommand is a standard command to view, change and reset the settings of a guild
+// changing a setting requires the permissions of the setting
+func SettingsCommand() Command {
+	const name = "settings"
+
+	return NewCommand(name, "Shows and changes the settings of the server", func(ctx Context) {
+		b := ctx.Bot()
+		guildID := ctx.MessageEvent().GuildID
+		args := strings.Fields(ctx.Message())
+
+		var err error
+		switch {
+		case len(args) == 0:
+			err = sendSettings(ctx)
+		case len(args) == 1:
+			err = sendSetting(ctx, args[0])
+		case args[0] == "set" && len(args) > 2, args[0] == "reset" && len(args) == 2:
+			var s *Setting
+			if s, err = b.Setting(args[1]); err != nil {
+				break
+			}
+			perms := s.Permissions
+			if perms == 0 {
+				perms = discordgo.PermissionManageServer
+			}
+			if err = RequirePermissions(perms)(ctx); err != nil {
+				break
+			}
+
+			if args[0] == "reset" {
+				err = b.ResetGuildSetting(guildID, s.Name)
+			} else {
+				var v interface{}
+				value := strings.TrimPrefix(strings.TrimSpace(strings.TrimPrefix(ctx.Message(), args[0])), args[1])
+				if v, err = s.Parse(value); err == nil {
+					err = b.SetGuildSetting(guildID, s.Name, v)
+				}
+			}
+			if err == nil {
+				err = sendSetting(ctx, s.Name)
+			}
+		default:
+			err = fmt.Errorf("usage: %s%s %s", b.Prefix(guildID), name, Details(ctx.Command()).Usage)
+		}
+
+		if err != nil {
+			_, _ = ctx.SendError(err)
+		}
+	},
+		WithCategory("Admin"),
+		WithUsage("[setting] | set <setting> <value> | reset <setting>"),
+		WithParameters(
+			Parameter{Name: "setting", Description: "The setting to show or change", Optional: true},
+			Parameter{Name: "value", Description: "The new value of the setting", Optional: true},
+		),
+		WithExamples(name, name+" prefix", name+" set prefix ?", name+" reset prefix"),
+		WithChecks(GuildOnly()),
+	)
+}
+
+// sendSettings sends the values of all settings in the guild
+func sendSettings(ctx Context) error {
+	b := ctx.Bot()
+	var lines []string
+	for _, s := range b.Settings() {
+		v, err := b.GuildSetting(ctx.MessageEvent().GuildID, s.Name)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, fmt.Sprintf("`%s` %s", s.Name, s.Format(v)))
+	}
+
+	pages := PaginateLines("Settings", "", lines, 15)
+	for _, p := range pages {
+		p.Embed.Color = b.Theme().Primary
+	}
+	return NewPaginator(pages...).Send(ctx)
+}
+
+// sendSetting sends the details of a setting and its value in the guild
+func sendSetting(ctx Context, name string) error {
+	b := ctx.Bot()
+	s, err := b.Setting(name)
+	if err != nil {
+		return err
+	}
+	v, err := b.GuildSetting(ctx.MessageEvent().GuildID, name)
+	if err != nil {
+		return err
+	}
+
+	e := b.NewEmbed().
+		SetTitle(s.Name).
+		SetDescription(s.Description).
+		AddField("Value", s.Format(v), true).
+		AddField("Default", s.Format(s.Default), true).
+		AddField("Type", s.Type.String(), true)
+	_, err = ctx.SendEmbed(e.Build())
+	return err
+}
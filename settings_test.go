@@ -0,0 +1,99 @@
+package fuzzy_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fvdveen/fuzzy"
+)
+
+func TestSettingParse(t *testing.T) {
+	tests := []struct {
+		name string
+		typ  fuzzy.SettingType
+		in   string
+		exp  interface{}
+		err  bool
+	}{
+		{name: "string", typ: fuzzy.StringSettingType, in: " hello ", exp: "hello"},
+		{name: "int", typ: fuzzy.IntSettingType, in: "42", exp: int64(42)},
+		{name: "invalid int", typ: fuzzy.IntSettingType, in: "many", err: true},
+		{name: "bool", typ: fuzzy.BoolSettingType, in: "Yes", exp: true},
+		{name: "channel mention", typ: fuzzy.ChannelSettingType, in: "<#1234>", exp: "1234"},
+		{name: "role id", typ: fuzzy.RoleSettingType, in: "5678", exp: "5678"},
+		{name: "invalid role", typ: fuzzy.RoleSettingType, in: "<#1234>", err: true},
+		{name: "list", typ: fuzzy.StringListSettingType, in: "ping, pong help", exp: []string{"ping", "pong", "help"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &fuzzy.Setting{Name: tt.name, Type: tt.typ}
+			v, err := s.Parse(tt.in)
+			if tt.err {
+				if err == nil {
+					t.Fatalf("expected an error got %v", v)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(v, tt.exp) {
+				t.Fatalf("expected %#v got %#v", tt.exp, v)
+			}
+		})
+	}
+}
+
+func TestGuildSetting(t *testing.T) {
+	b, err := fuzzy.New(fuzzy.WithPrefix("!"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.RegisterSetting(fuzzy.Setting{
+		Name:    "volume",
+		Type:    fuzzy.IntSettingType,
+		Default: int64(50),
+		Validate: func(v interface{}) error {
+			if i := v.(int64); i < 0 || i > 100 {
+				return fuzzy.ErrInvalidSetting
+			}
+			return nil
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := b.RegisterSetting(fuzzy.Setting{Name: "volume"}); err == nil {
+		t.Fatal("registered a duplicate setting")
+	}
+	if err := b.SetGuildSetting("1", "volume", 200); err != fuzzy.ErrInvalidSetting {
+		t.Fatalf("expected %v got %v", fuzzy.ErrInvalidSetting, err)
+	}
+	if err := b.SetGuildSetting("1", "volume", 80); err != nil {
+		t.Fatal(err)
+	}
+
+	for guild, exp := range map[string]int64{"1": 80, "2": 50} {
+		v, err := b.GuildSetting(guild, "volume")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != exp {
+			t.Fatalf("expected %d in guild %s got %#v", exp, guild, v)
+		}
+	}
+
+	if err := b.SetGuildSetting("1", fuzzy.PrefixSetting, "?"); err != nil {
+		t.Fatal(err)
+	}
+	if p := b.Prefix("1"); p != "?" {
+		t.Fatalf("expected prefix ? got %s", p)
+	}
+	if err := b.ResetGuildSetting("1", fuzzy.PrefixSetting); err != nil {
+		t.Fatal(err)
+	}
+	if p := b.Prefix("1"); p != "!" {
+		t.Fatalf("expected prefix ! got %s", p)
+	}
+}
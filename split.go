@@ -0,0 +1,137 @@
+package fuzzy
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// MaxMessageLength is the maximum amount of characters in a discord message
+	MaxMessageLength = 2000
+
+	codeFence = "```"
+	// maxCodeLanguage is the maximum length of the language of a re-opened code block
+	maxCodeLanguage = 16
+)
+
+// SplitMessage splits msg into chunks of at most max characters
+// it splits on line boundaries, then on word boundaries and only cuts words as last resort
+// code blocks that span multiple chunks are closed and re-opened in the next chunk
+func SplitMessage(msg string, max int) []string {
+	if utf8.RuneCountInString(msg) <= max {
+		return []string{msg}
+	}
+
+	var (
+		chunks []string
+		lines  []string
+		length int
+		fence  string
+		// fresh is set when the chunk only holds the re-opened code fence
+		fresh bool
+	)
+	closeLen := len(codeFence) + 1
+
+	flush := func(closeFence bool) {
+		if closeFence && fence != "" {
+			lines = append(lines, codeFence)
+		}
+		chunks = append(chunks, strings.Join(lines, "\n"))
+		lines, length, fresh = nil, 0, false
+		if fence != "" {
+			lines = []string{fence}
+			length = utf8.RuneCountInString(fence)
+			fresh = true
+		}
+	}
+
+	for _, line := range strings.Split(msg, "\n") {
+		after := fence
+		if strings.Count(line, codeFence)%2 == 1 {
+			if fence != "" {
+				after = ""
+			} else {
+				after = codeFence + codeLanguage(line[strings.LastIndex(line, codeFence)+len(codeFence):])
+			}
+		}
+		closing := 0
+		if after != "" {
+			closing = closeLen
+		}
+
+		l := utf8.RuneCountInString(line)
+		if len(lines) > 0 && !fresh && length+1+l+closing > max {
+			flush(true)
+		}
+
+		for {
+			sep := 0
+			if len(lines) > 0 {
+				sep = 1
+			}
+			room := max - length - sep - closing
+			if room < 1 {
+				room = 1
+			}
+			if l <= room {
+				break
+			}
+
+			var p string
+			p, line = cutLine(line, room)
+			l = utf8.RuneCountInString(line)
+			lines = append(lines, p)
+			// the rest of a cut opening line is in the code block
+			if fence == "" && strings.Count(p, codeFence)%2 == 1 {
+				fence = after
+			}
+			flush(true)
+		}
+
+		if len(lines) > 0 {
+			length++
+		}
+		length += l
+		lines = append(lines, line)
+		fresh = false
+		fence = after
+	}
+	if len(lines) > 0 && !fresh {
+		flush(false)
+	}
+
+	return chunks
+}
+
+// codeLanguage returns the language of the info string of a code fence
+// it is cut off at maxCodeLanguage characters so the re-opened fence always fits in a chunk
+func codeLanguage(info string) string {
+	fs := strings.Fields(info)
+	if len(fs) == 0 {
+		return ""
+	}
+	rs := []rune(fs[0])
+	if len(rs) > maxCodeLanguage {
+		rs = rs[:maxCodeLanguage]
+	}
+	return string(rs)
+}
+
+// cutLine cuts the first n characters of line off on a word boundary
+func cutLine(line string, n int) (string, string) {
+	rs := []rune(line)
+	if n >= len(rs) {
+		return line, ""
+	}
+	if n <= 0 {
+		return "", line
+	}
+
+	for i := n; i > 0; i-- {
+		if rs[i] == ' ' {
+			return string(rs[:i]), string(rs[i+1:])
+		}
+	}
+
+	return string(rs[:n]), string(rs[n:])
+}
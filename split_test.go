@@ -0,0 +1,108 @@
+package fuzzy_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/fvdveen/fuzzy"
+)
+
+var splitTests = []struct {
+	msg string
+	max int
+	res []string
+}{
+	{
+		msg: "short message",
+		max: 20,
+		res: []string{"short message"},
+	},
+	{
+		msg: "first line\nsecond line\nthird line",
+		max: 25,
+		res: []string{"first line\nsecond line", "third line"},
+	},
+	{
+		msg: "some words that do not fit",
+		max: 10,
+		res: []string{"some words", "that do", "not fit"},
+	},
+	{
+		msg: "abcdefghijklmnopqrstuvwxyz",
+		max: 10,
+		res: []string{"abcdefghij", "klmnopqrst", "uvwxyz"},
+	},
+	{
+		msg: "```go title=\"main.go\"\nline 1\nline 2\n```",
+		max: 30,
+		res: []string{"```go title=\"main.go\"\n```", "```go\nline 1\nline 2\n```"},
+	},
+	{
+		msg: "text\n```go\nline 1\nline 2\nline 3\n```\nafter",
+		max: 24,
+		res: []string{"text\n```go\nline 1\n```", "```go\nline 2\nline 3\n```", "after"},
+	},
+}
+
+func TestSplitMessage(t *testing.T) {
+	for _, test := range splitTests {
+		res := fuzzy.SplitMessage(test.msg, test.max)
+		if !reflect.DeepEqual(res, test.res) {
+			t.Errorf("expected: %q got: %q", test.res, res)
+		}
+	}
+}
+
+func TestSplitMessageLimits(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("intro\n```\n")
+	for i := 0; i < 500; i++ {
+		b.WriteString("fmt.Println(\"a fairly long line of code in a block\")\n")
+	}
+	b.WriteString("```\n")
+	b.WriteString(strings.Repeat("wörds ", 1000))
+
+	for _, c := range fuzzy.SplitMessage(b.String(), fuzzy.MaxMessageLength) {
+		if l := utf8.RuneCountInString(c); l > fuzzy.MaxMessageLength {
+			t.Errorf("chunk of length %d exceeds maximum of %d", l, fuzzy.MaxMessageLength)
+		}
+		if strings.Count(c, "```")%2 != 0 {
+			t.Errorf("chunk has unbalanced code fences: %q", c)
+		}
+	}
+}
+
+func TestSplitMessageLongFence(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  string
+		max  int
+	}{
+		{"long info string", "```" + strings.Repeat("x", 2100) + "\n" + strings.Repeat("a b ", 600) + "\n```", fuzzy.MaxMessageLength},
+		{"long language", "```" + strings.Repeat("x", 30) + "\n" + strings.Repeat("a b ", 20) + "\n```", 30},
+		{"tiny chunks", "```go\n\n\nabc\n```", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := fuzzy.SplitMessage(tt.msg, tt.max)
+			if len(cs) < 2 {
+				t.Fatalf("expected the message to be split got %q", cs)
+			}
+			// chunks can not hold a code block when they are this small
+			if tt.max < 10 {
+				return
+			}
+			for _, c := range cs {
+				if l := utf8.RuneCountInString(c); l > tt.max {
+					t.Errorf("chunk of length %d exceeds maximum of %d", l, tt.max)
+				}
+				if strings.Count(c, "```")%2 != 0 {
+					t.Errorf("chunk has unbalanced code fences: %q", c)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package fuzzy
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Store is a key value store used by the bot and its commands to keep data
+type Store interface {
+	// Get returns the value of the key or ErrNotFound
+	Get(key string) ([]byte, error)
+	// Set sets the value of the key, it expires after ttl unless ttl is 0
+	Set(key string, value []byte, ttl time.Duration) error
+	// Delete removes the key, it does not fail if the key does not exist
+	Delete(key string) error
+	// List returns all entries with keys starting with prefix ordered by key
+	List(prefix string) ([]Entry, error)
+	// CompareAndSwap sets the value of the key to new if its current value is old
+	// a nil old value means the key must not exist
+	CompareAndSwap(key string, old, new []byte, ttl time.Duration) (bool, error)
+}
+
+// Entry is a single key and value in a Store
+type Entry struct {
+	Key   string
+	Value []byte
+}
+
+// item is a value in a MemoryStore
+type item struct {
+	value   []byte
+	expires time.Time
+}
+
+// expired reports if the item is expired at t
+func (i item) expired(t time.Time) bool {
+	return !i.expires.IsZero() && !t.Before(i.expires)
+}
+
+// MemoryStore is a Store which keeps everything in memory
+type MemoryStore struct {
+	mu    sync.RWMutex
+	items map[string]item
+}
+
+// NewMemoryStore creates a new MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		items: make(map[string]item),
+	}
+}
+
+// Get implements Store
+func (s *MemoryStore) Get(key string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i, ok := s.items[key]
+	if !ok || i.expired(time.Now()) {
+		return nil, ErrNotFound
+	}
+	return append([]byte(nil), i.value...), nil
+}
+
+// Set implements Store
+func (s *MemoryStore) Set(key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.items[key] = newItem(value, ttl)
+	return nil
+}
+
+// Delete implements Store
+func (s *MemoryStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.items, key)
+	return nil
+}
+
+// List implements Store, expired keys are removed while listing
+func (s *MemoryStore) List(prefix string) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var es []Entry
+	for k, i := range s.items {
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+		if i.expired(now) {
+			delete(s.items, k)
+			continue
+		}
+		es = append(es, Entry{Key: k, Value: append([]byte(nil), i.value...)})
+	}
+	sort.Slice(es, func(i, j int) bool {
+		return es[i].Key < es[j].Key
+	})
+	return es, nil
+}
+
+// CompareAndSwap implements Store
+func (s *MemoryStore) CompareAndSwap(key string, old, new []byte, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.matches(key, old) {
+		return false, nil
+	}
+	s.items[key] = newItem(new, ttl)
+	return true, nil
+}
+
+// matches checks if the current value of the key is old, it must be called with mu held
+func (s *MemoryStore) matches(key string, old []byte) bool {
+	i, ok := s.items[key]
+	if ok && i.expired(time.Now()) {
+		ok = false
+	}
+	if old == nil {
+		return !ok
+	}
+	return ok && bytes.Equal(i.value, old)
+}
+
+func newItem(value []byte, ttl time.Duration) item {
+	i := item{value: append([]byte(nil), value...)}
+	if ttl > 0 {
+		i.expires = time.Now().Add(ttl)
+	}
+	return i
+}
+
+// namespacedStore prefixes all keys of a store
+type namespacedStore struct {
+	s      Store
+	prefix string
+}
+
+// Namespace returns a store which keeps its keys under the namespace in s
+func Namespace(s Store, namespace string) Store {
+	return &namespacedStore{
+		s:      s,
+		prefix: namespace + "/",
+	}
+}
+
+func (n *namespacedStore) Get(key string) ([]byte, error) {
+	return n.s.Get(n.prefix + key)
+}
+
+func (n *namespacedStore) Set(key string, value []byte, ttl time.Duration) error {
+	return n.s.Set(n.prefix+key, value, ttl)
+}
+
+func (n *namespacedStore) Delete(key string) error {
+	return n.s.Delete(n.prefix + key)
+}
+
+func (n *namespacedStore) List(prefix string) ([]Entry, error) {
+	es, err := n.s.List(n.prefix + prefix)
+	if err != nil {
+		return nil, err
+	}
+	for i := range es {
+		es[i].Key = strings.TrimPrefix(es[i].Key, n.prefix)
+	}
+	return es, nil
+}
+
+func (n *namespacedStore) CompareAndSwap(key string, old, new []byte, ttl time.Duration) (bool, error) {
+	return n.s.CompareAndSwap(n.prefix+key, old, new, ttl)
+}
+
+// Store returns the bot's store
+func (b *Bot) Store() Store {
+	return b.store
+}
+
+// GuildStore returns the part of the bot's store for the guild
+// direct messages have no guild, without a guild ID every call of the store fails with ErrNoGuild
+func (b *Bot) GuildStore(guildID string) Store {
+	if guildID == "" {
+		return noGuildStore{}
+	}
+	return Namespace(b.store, "guild/"+guildID)
+}
+
+// noGuildStore is the store of a guild without an ID
+// it fails instead of letting all direct messages share one namespace
+type noGuildStore struct{}
+
+func (noGuildStore) Get(string) ([]byte, error) {
+	return nil, ErrNoGuild
+}
+
+func (noGuildStore) Set(string, []byte, time.Duration) error {
+	return ErrNoGuild
+}
+
+func (noGuildStore) Delete(string) error {
+	return ErrNoGuild
+}
+
+func (noGuildStore) List(string) ([]Entry, error) {
+	return nil, ErrNoGuild
+}
+
+func (noGuildStore) CompareAndSwap(string, []byte, []byte, time.Duration) (bool, error) {
+	return false, ErrNoGuild
+}
+
+// UserStore returns the part of the bot's store for the user
+func (b *Bot) UserStore(userID string) Store {
+	return Namespace(b.store, "user/"+userID)
+}
@@ -0,0 +1,103 @@
+package fuzzy_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fvdveen/fuzzy"
+)
+
+func TestMemoryStore(t *testing.T) {
+	s := fuzzy.NewMemoryStore()
+
+	if _, err := s.Get("a"); err != fuzzy.ErrNotFound {
+		t.Fatalf("expected %v got %v", fuzzy.ErrNotFound, err)
+	}
+
+	ok, err := s.CompareAndSwap("a", nil, []byte("1"), 0)
+	if err != nil || !ok {
+		t.Fatalf("could not swap a missing key: %v %v", ok, err)
+	}
+	ok, err = s.CompareAndSwap("a", nil, []byte("2"), 0)
+	if err != nil || ok {
+		t.Fatalf("swapped an existing key as missing: %v %v", ok, err)
+	}
+	ok, err = s.CompareAndSwap("a", []byte("1"), []byte("2"), 0)
+	if err != nil || !ok {
+		t.Fatalf("could not swap a matching key: %v %v", ok, err)
+	}
+	if v, err := s.Get("a"); err != nil || string(v) != "2" {
+		t.Fatalf("expected 2 got %q %v", v, err)
+	}
+
+	if err := s.Set("b", []byte("3"), time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if _, err := s.Get("b"); err != fuzzy.ErrNotFound {
+		t.Fatalf("expected expired key to be gone, got %v", err)
+	}
+
+	if err := s.Delete("a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get("a"); err != fuzzy.ErrNotFound {
+		t.Fatalf("expected deleted key to be gone, got %v", err)
+	}
+}
+
+func TestNamespace(t *testing.T) {
+	s := fuzzy.NewMemoryStore()
+	g1 := fuzzy.Namespace(s, "guild/1")
+	g2 := fuzzy.Namespace(s, "guild/2")
+
+	for k, v := range map[string]string{"prefix": "!", "locale": "en"} {
+		if err := g1.Set(k, []byte(v), 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := g2.Set("prefix", []byte("?"), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	es, err := g1.List("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []fuzzy.Entry{
+		{Key: "locale", Value: []byte("en")},
+		{Key: "prefix", Value: []byte("!")},
+	}
+	if !reflect.DeepEqual(es, exp) {
+		t.Fatalf("expected %v got %v", exp, es)
+	}
+
+	if v, err := s.Get("guild/2/prefix"); err != nil || string(v) != "?" {
+		t.Fatalf("expected ? got %q %v", v, err)
+	}
+}
+
+func TestGuildStoreWithoutGuild(t *testing.T) {
+	b, err := fuzzy.New(fuzzy.WithConfig(&fuzzy.Config{Token: "token", Prefix: "!"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := b.GuildStore("")
+	if err := s.Set("prefix", []byte("?"), 0); err != fuzzy.ErrNoGuild {
+		t.Fatalf("expected error %v got %v", fuzzy.ErrNoGuild, err)
+	}
+	if _, err := s.Get("prefix"); err != fuzzy.ErrNoGuild {
+		t.Fatalf("expected error %v got %v", fuzzy.ErrNoGuild, err)
+	}
+	if _, err := s.List(""); err != fuzzy.ErrNoGuild {
+		t.Fatalf("expected error %v got %v", fuzzy.ErrNoGuild, err)
+	}
+	if es, err := b.Store().List(""); err != nil || len(es) != 0 {
+		t.Fatalf("expected nothing to be stored got %v %v", es, err)
+	}
+	if err := b.SetGuildSetting("", fuzzy.DisabledCommandsSetting, []string{"ping"}); err == nil {
+		t.Fatal("expected an error when changing the settings without a guild")
+	}
+}
@@ -0,0 +1,272 @@
+package stores
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fvdveen/fuzzy"
+)
+
+const (
+	opSet    = "set"
+	opDelete = "delete"
+)
+
+// record is a single line in the log file
+type record struct {
+	Op      string    `json:"op"`
+	Key     string    `json:"key"`
+	Value   []byte    `json:"value,omitempty"`
+	Expires time.Time `json:"expires"`
+}
+
+func (r record) expired(t time.Time) bool {
+	return !r.Expires.IsZero() && !t.Before(r.Expires)
+}
+
+// FileStore is a fuzzy.Store which keeps its data in memory and appends all changes to a file
+// the file is compacted when the store is opened and when Compact is called
+type FileStore struct {
+	path string
+
+	mu      sync.RWMutex
+	f       *os.File
+	records map[string]record
+}
+
+// NewFileStore opens the store in the file at path, the file is created if it does not exist
+func NewFileStore(path string) (*FileStore, error) {
+	s := &FileStore{
+		path:    path,
+		records: make(map[string]record),
+	}
+
+	if err := s.load(); err != nil {
+		return nil, fmt.Errorf("could not load %s: %v", path, err)
+	}
+	if err := s.Compact(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// load replays the log file
+func (s *FileStore) load() error {
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	now := time.Now()
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 64*1024*1024)
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var r record
+		if err := json.Unmarshal(line, &r); err != nil {
+			// the last line is incomplete when the bot stopped while writing it
+			if !sc.Scan() {
+				break
+			}
+			return fmt.Errorf("invalid record: %v", err)
+		}
+
+		switch r.Op {
+		case opSet:
+			if r.expired(now) {
+				delete(s.records, r.Key)
+				continue
+			}
+			s.records[r.Key] = r
+		case opDelete:
+			delete(s.records, r.Key)
+		default:
+			return fmt.Errorf("unknown operation %q", r.Op)
+		}
+	}
+	return sc.Err()
+}
+
+// Compact rewrites the file so it only contains the current values
+func (s *FileStore) Compact() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tmp := s.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("could not create %s: %v", tmp, err)
+	}
+
+	now := time.Now()
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for k, r := range s.records {
+		if r.expired(now) {
+			delete(s.records, k)
+			continue
+		}
+		if err := enc.Encode(r); err != nil {
+			f.Close()
+			return fmt.Errorf("could not write %s: %v", tmp, err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return fmt.Errorf("could not write %s: %v", tmp, err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("could not sync %s: %v", tmp, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("could not close %s: %v", tmp, err)
+	}
+
+	if s.f != nil {
+		s.f.Close()
+		s.f = nil
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("could not replace %s: %v", s.path, err)
+	}
+
+	s.f, err = os.OpenFile(s.path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("could not open %s: %v", s.path, err)
+	}
+	return nil
+}
+
+// Close closes the file of the store
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.f == nil {
+		return nil
+	}
+	err := s.f.Close()
+	s.f = nil
+	return err
+}
+
+// append writes the record to the log and applies it, it must be called with mu held
+func (s *FileStore) append(r record) error {
+	if s.f == nil {
+		return os.ErrClosed
+	}
+
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if _, err := s.f.Write(append(b, '\n')); err != nil {
+		return fmt.Errorf("could not write %s: %v", s.path, err)
+	}
+	if err := s.f.Sync(); err != nil {
+		return fmt.Errorf("could not sync %s: %v", s.path, err)
+	}
+
+	if r.Op == opDelete {
+		delete(s.records, r.Key)
+	} else {
+		s.records[r.Key] = r
+	}
+	return nil
+}
+
+// Get implements fuzzy.Store
+func (s *FileStore) Get(key string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	r, ok := s.records[key]
+	if !ok || r.expired(time.Now()) {
+		return nil, fuzzy.ErrNotFound
+	}
+	return append([]byte(nil), r.Value...), nil
+}
+
+// Set implements fuzzy.Store
+func (s *FileStore) Set(key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.append(newRecord(key, value, ttl))
+}
+
+// Delete implements fuzzy.Store
+func (s *FileStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.records[key]; !ok {
+		return nil
+	}
+	return s.append(record{Op: opDelete, Key: key})
+}
+
+// List implements fuzzy.Store
+func (s *FileStore) List(prefix string) ([]fuzzy.Entry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	var es []fuzzy.Entry
+	for k, r := range s.records {
+		if strings.HasPrefix(k, prefix) && !r.expired(now) {
+			es = append(es, fuzzy.Entry{Key: k, Value: append([]byte(nil), r.Value...)})
+		}
+	}
+	sort.Slice(es, func(i, j int) bool {
+		return es[i].Key < es[j].Key
+	})
+	return es, nil
+}
+
+// CompareAndSwap implements fuzzy.Store
+func (s *FileStore) CompareAndSwap(key string, old, new []byte, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.records[key]
+	if ok && r.expired(time.Now()) {
+		ok = false
+	}
+	if (old == nil && ok) || (old != nil && (!ok || !bytes.Equal(r.Value, old))) {
+		return false, nil
+	}
+
+	if err := s.append(newRecord(key, new, ttl)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func newRecord(key string, value []byte, ttl time.Duration) record {
+	r := record{
+		Op:    opSet,
+		Key:   key,
+		Value: append([]byte(nil), value...),
+	}
+	if ttl > 0 {
+		r.Expires = time.Now().Add(ttl)
+	}
+	return r
+}
@@ -0,0 +1,66 @@
+package stores_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fvdveen/fuzzy"
+	stores "github.com/fvdveen/fuzzy/stores/file"
+)
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.log")
+
+	s, err := stores.NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set("kept", []byte("1"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set("deleted", []byte("2"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete("deleted"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set("expired", []byte("3"), time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := s.CompareAndSwap("kept", []byte("1"), []byte("4"), 0); err != nil || !ok {
+		t.Fatalf("could not swap: %v %v", ok, err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// a partially written record at the end of the log is ignored
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(`{"op":"set","key":"par`); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	time.Sleep(5 * time.Millisecond)
+
+	s, err = stores.NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	es, err := s.List("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(es) != 1 || es[0].Key != "kept" || string(es[0].Value) != "4" {
+		t.Fatalf("expected only kept=4 got %v", es)
+	}
+	if _, err := s.Get("deleted"); err != fuzzy.ErrNotFound {
+		t.Fatalf("expected %v got %v", fuzzy.ErrNotFound, err)
+	}
+}
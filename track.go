@@ -0,0 +1,113 @@
+package fuzzy
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FrameDuration is the duration of a single opus frame sent to discord
+const FrameDuration = 20 * time.Millisecond
+
+// ProgressBarWidth is the amount of characters in the progress bar of NowPlayingEmbed
+const ProgressBarWidth = 20
+
+// Metadata describes the track of a voice item
+type Metadata struct {
+	Title    string
+	Artist   string
+	Duration time.Duration
+	// URL is the source of the track
+	URL       string
+	Thumbnail string
+	// RequesterID is the ID of the user who requested the track
+	RequesterID string
+}
+
+// MetadataItem is a VoiceItem which describes its track
+type MetadataItem interface {
+	VoiceItem
+	Metadata() Metadata
+}
+
+// ItemMetadata returns the metadata of the item
+// items which do not implement MetadataItem are called "Unknown track"
+func ItemMetadata(vi VoiceItem) Metadata {
+	var md Metadata
+	if mi, ok := vi.(MetadataItem); ok {
+		md = mi.Metadata()
+	}
+	if md.Title == "" {
+		md.Title = "Unknown track"
+	}
+	return md
+}
+
+// ProgressBar draws how far a track has been played followed by the position and duration
+// when the duration is not known only the position is given
+func ProgressBar(pos, dur time.Duration, width int) string {
+	if dur <= 0 {
+		return formatTrackTime(pos)
+	}
+	if pos > dur {
+		pos = dur
+	}
+	if width < 2 {
+		width = 2
+	}
+
+	i := int(int64(pos) * int64(width-1) / int64(dur))
+	bar := strings.Repeat("▬", i) + "🔘" + strings.Repeat("▬", width-1-i)
+	return fmt.Sprintf("%s %s / %s", bar, formatTrackTime(pos), formatTrackTime(dur))
+}
+
+// formatTrackTime formats the duration as m:ss or h:mm:ss
+func formatTrackTime(d time.Duration) string {
+	s := int(d / time.Second)
+	if s >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+	}
+	return fmt.Sprintf("%d:%02d", s/60, s%60)
+}
+
+// NowPlayingEmbed creates an embed describing the item and how far it has been played
+func NowPlayingEmbed(vi VoiceItem, pos time.Duration) *EmbedBuilder {
+	md := ItemMetadata(vi)
+
+	title := md.Title
+	if md.URL != "" {
+		title = fmt.Sprintf("[%s](%s)", md.Title, md.URL)
+	}
+	if md.Artist != "" {
+		title += " by " + md.Artist
+	}
+
+	e := NewEmbed().
+		SetTitle("Now playing").
+		SetDescription(title + "\n" + ProgressBar(pos, md.Duration, ProgressBarWidth))
+	if md.Thumbnail != "" {
+		e.SetThumbnail(md.Thumbnail)
+	}
+	if md.RequesterID != "" {
+		e.AddField("Requested by", "<@"+md.RequesterID+">", true)
+	}
+	return e
+}
+
+// NowPlayingCommand creates a command showing the track that is playing in the server
+func NowPlayingCommand() Command {
+	return NewCommand("nowplaying", "Shows the track that is playing", func(ctx Context) {
+		vh, err := ctx.VoiceHandler()
+		if err != nil {
+			_, _ = ctx.SendError(ErrNothingPlaying)
+			return
+		}
+		vi := vh.NowPlaying()
+		if vi == nil {
+			_, _ = ctx.SendError(ErrNothingPlaying)
+			return
+		}
+
+		_, _ = ctx.SendEmbed(NowPlayingEmbed(vi, vh.Position()).SetColor(ctx.Bot().Theme().Primary).Build())
+	}, WithAliases("np"), WithChecks(GuildOnly()))
+}
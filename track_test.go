@@ -0,0 +1,91 @@
+package fuzzy_test
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/fvdveen/fuzzy"
+)
+
+type track struct {
+	md fuzzy.Metadata
+}
+
+func (t track) OpusFrame() ([]byte, error) {
+	return nil, io.EOF
+}
+
+func (t track) ResetPlayback() {}
+
+func (t track) Metadata() fuzzy.Metadata {
+	return t.md
+}
+
+type silence struct{}
+
+func (silence) OpusFrame() ([]byte, error) {
+	return nil, io.EOF
+}
+
+func (silence) ResetPlayback() {}
+
+func TestProgressBar(t *testing.T) {
+	tests := []struct {
+		pos, dur time.Duration
+		width    int
+		exp      string
+	}{
+		{0, time.Minute, 5, "🔘▬▬▬▬ 0:00 / 1:00"},
+		{30 * time.Second, time.Minute, 5, "▬▬🔘▬▬ 0:30 / 1:00"},
+		{time.Minute, time.Minute, 5, "▬▬▬▬🔘 1:00 / 1:00"},
+		{2 * time.Minute, time.Minute, 5, "▬▬▬▬🔘 1:00 / 1:00"},
+		{90 * time.Minute, 2 * time.Hour, 3, "▬🔘▬ 1:30:00 / 2:00:00"},
+		{75 * time.Second, 0, 5, "1:15"},
+	}
+
+	for _, tt := range tests {
+		if s := fuzzy.ProgressBar(tt.pos, tt.dur, tt.width); s != tt.exp {
+			t.Errorf("expected %q for %v of %v got %q", tt.exp, tt.pos, tt.dur, s)
+		}
+	}
+}
+
+func TestNowPlayingEmbed(t *testing.T) {
+	tests := []struct {
+		name  string
+		item  fuzzy.VoiceItem
+		desc  string
+		field string
+	}{
+		{
+			name: "metadata",
+			item: track{fuzzy.Metadata{
+				Title:       "Song",
+				Artist:      "Band",
+				Duration:    4 * time.Second,
+				URL:         "https://example.com/song",
+				RequesterID: "42",
+			}},
+			desc:  "[Song](https://example.com/song) by Band\n" + fuzzy.ProgressBar(time.Second, 4*time.Second, fuzzy.ProgressBarWidth),
+			field: "<@42>",
+		},
+		{
+			name: "no metadata",
+			item: silence{},
+			desc: "Unknown track\n0:01",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := fuzzy.NowPlayingEmbed(tt.item, time.Second).Build()
+			if e.Description != tt.desc {
+				t.Fatalf("expected description %q got %q", tt.desc, e.Description)
+			}
+			if tt.field == "" && len(e.Fields) != 0 || tt.field != "" && (len(e.Fields) != 1 || e.Fields[0].Value != tt.field) {
+				t.Fatalf("expected requester %q got %v", tt.field, e.Fields)
+			}
+		})
+	}
+}
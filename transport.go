@@ -0,0 +1,133 @@
+package fuzzy
+
+import (
+	"github.com/bwmarrin/discordgo"
+)
+
+// TransportGenerator creates the Transport of the bot from its session
+type TransportGenerator func(*discordgo.Session) Transport
+
+// Transport is the part of discord used by the bot
+// it covers the REST calls, the state lookups and joining voice channels
+type Transport interface {
+	ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend) (*discordgo.Message, error)
+	ChannelMessageEditComplex(m *discordgo.MessageEdit) (*discordgo.Message, error)
+	ChannelMessageDelete(channelID, messageID string) error
+	ChannelTyping(channelID string) error
+	MessageReactionAdd(channelID, messageID, emoji string) error
+	MessageReactionRemove(channelID, messageID, emoji, userID string) error
+	MessageReactionsRemoveAll(channelID, messageID string) error
+	UserChannelCreate(userID string) (*discordgo.Channel, error)
+
+	Channel(channelID string) (*discordgo.Channel, error)
+	Guild(guildID string) (*discordgo.Guild, error)
+	GuildMember(guildID, userID string) (*discordgo.Member, error)
+	UserChannelPermissions(userID, channelID string) (int64, error)
+	// VoiceState returns the voice state of the user in the guild or ErrUnknownVoiceState
+	VoiceState(guildID, userID string) (*discordgo.VoiceState, error)
+	// BotUser returns the user of the bot
+	BotUser() *discordgo.User
+
+	ChannelVoiceJoin(guildID, channelID string, mute, deaf bool) (VoiceConnection, error)
+}
+
+// VoiceConnection is a connection to a voice channel
+type VoiceConnection interface {
+	Speaking(bool) error
+	// OpusSend returns the channel the opus frames are sent on
+	OpusSend() chan<- []byte
+	Disconnect() error
+}
+
+// discordgoTransport implements Transport on a discordgo session
+// every method forwards explicitly, the REST methods of discordgo take extra request options
+type discordgoTransport struct {
+	*discordgo.Session
+}
+
+// DefaultTransport is the default transport which uses the discordgo session
+func DefaultTransport(s *discordgo.Session) Transport {
+	return discordgoTransport{Session: s}
+}
+
+func (t discordgoTransport) ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend) (*discordgo.Message, error) {
+	return t.Session.ChannelMessageSendComplex(channelID, data)
+}
+
+func (t discordgoTransport) ChannelMessageEditComplex(m *discordgo.MessageEdit) (*discordgo.Message, error) {
+	return t.Session.ChannelMessageEditComplex(m)
+}
+
+func (t discordgoTransport) ChannelMessageDelete(channelID, messageID string) error {
+	return t.Session.ChannelMessageDelete(channelID, messageID)
+}
+
+func (t discordgoTransport) ChannelTyping(channelID string) error {
+	return t.Session.ChannelTyping(channelID)
+}
+
+func (t discordgoTransport) MessageReactionAdd(channelID, messageID, emoji string) error {
+	return t.Session.MessageReactionAdd(channelID, messageID, emoji)
+}
+
+func (t discordgoTransport) MessageReactionRemove(channelID, messageID, emoji, userID string) error {
+	return t.Session.MessageReactionRemove(channelID, messageID, emoji, userID)
+}
+
+func (t discordgoTransport) MessageReactionsRemoveAll(channelID, messageID string) error {
+	return t.Session.MessageReactionsRemoveAll(channelID, messageID)
+}
+
+func (t discordgoTransport) UserChannelCreate(userID string) (*discordgo.Channel, error) {
+	return t.Session.UserChannelCreate(userID)
+}
+
+func (t discordgoTransport) Channel(channelID string) (*discordgo.Channel, error) {
+	return t.Session.Channel(channelID)
+}
+
+func (t discordgoTransport) Guild(guildID string) (*discordgo.Guild, error) {
+	return t.Session.Guild(guildID)
+}
+
+func (t discordgoTransport) GuildMember(guildID, userID string) (*discordgo.Member, error) {
+	return t.Session.GuildMember(guildID, userID)
+}
+
+func (t discordgoTransport) UserChannelPermissions(userID, channelID string) (int64, error) {
+	return t.Session.UserChannelPermissions(userID, channelID)
+}
+
+func (t discordgoTransport) VoiceState(guildID, userID string) (*discordgo.VoiceState, error) {
+	g, err := t.State.Guild(guildID)
+	if err != nil {
+		return nil, err
+	}
+	for _, vs := range g.VoiceStates {
+		if vs.UserID == userID {
+			return vs, nil
+		}
+	}
+	return nil, ErrUnknownVoiceState
+}
+
+func (t discordgoTransport) BotUser() *discordgo.User {
+	return t.State.User
+}
+
+func (t discordgoTransport) ChannelVoiceJoin(guildID, channelID string, mute, deaf bool) (VoiceConnection, error) {
+	vc, err := t.Session.ChannelVoiceJoin(guildID, channelID, mute, deaf)
+	if err != nil {
+		return nil, err
+	}
+	return discordgoVoiceConnection{vc}, nil
+}
+
+// discordgoVoiceConnection implements VoiceConnection on a discordgo voice connection
+type discordgoVoiceConnection struct {
+	*discordgo.VoiceConnection
+}
+
+func (vc discordgoVoiceConnection) OpusSend() chan<- []byte {
+	return vc.VoiceConnection.OpusSend
+}
@@ -0,0 +1,123 @@
+package fuzzy_test
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/fvdveen/fuzzy"
+)
+
+func TestDefaultTransportVoiceState(t *testing.T) {
+	s := &discordgo.Session{State: discordgo.NewState()}
+	s.State.User = &discordgo.User{ID: "1000", Bot: true}
+	if err := s.State.GuildAdd(&discordgo.Guild{
+		ID:          "2000",
+		VoiceStates: []*discordgo.VoiceState{{GuildID: "2000", ChannelID: "3000", UserID: "4000"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	tr := fuzzy.DefaultTransport(s)
+
+	tests := []struct {
+		name    string
+		guild   string
+		user    string
+		channel string
+		err     error
+	}{
+		{name: "connected", guild: "2000", user: "4000", channel: "3000"},
+		{name: "not connected", guild: "2000", user: "4001", err: fuzzy.ErrUnknownVoiceState},
+		{name: "unknown guild", guild: "2001", user: "4000", err: discordgo.ErrStateNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vs, err := tr.VoiceState(tt.guild, tt.user)
+			if err != tt.err {
+				t.Fatalf("expected error %v got %v", tt.err, err)
+			}
+			if err == nil && vs.ChannelID != tt.channel {
+				t.Fatalf("expected channel %s got %s", tt.channel, vs.ChannelID)
+			}
+		})
+	}
+
+	if u := tr.BotUser(); u == nil || u.ID != "1000" {
+		t.Fatalf("expected the user of the state got %v", u)
+	}
+}
+
+// fakeTransport answers the calls of the bot without a discord session
+type fakeTransport struct {
+	fuzzy.Transport
+	sent  []*discordgo.MessageSend
+	voice map[string]*discordgo.VoiceState
+}
+
+func (t *fakeTransport) ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend) (*discordgo.Message, error) {
+	t.sent = append(t.sent, data)
+	return &discordgo.Message{ID: "5000", ChannelID: channelID, Content: data.Content}, nil
+}
+
+func (t *fakeTransport) VoiceState(guildID, userID string) (*discordgo.VoiceState, error) {
+	if vs, ok := t.voice[guildID+"/"+userID]; ok {
+		return vs, nil
+	}
+	return nil, fuzzy.ErrUnknownVoiceState
+}
+
+func (t *fakeTransport) BotUser() *discordgo.User {
+	return &discordgo.User{ID: "1000", Bot: true}
+}
+
+func TestTransport(t *testing.T) {
+	ft := &fakeTransport{voice: map[string]*discordgo.VoiceState{
+		"2000/4000": {GuildID: "2000", ChannelID: "3001", UserID: "4000"},
+	}}
+	b, err := fuzzy.New(
+		fuzzy.WithConfig(&fuzzy.Config{Token: "token", Prefix: "!", LogLevel: fuzzy.LogError}),
+		func(b *fuzzy.Bot) {
+			b.Generator().SetTransportGenerator(func(s *discordgo.Session) fuzzy.Transport {
+				ft.Transport = fuzzy.DefaultTransport(s)
+				return ft
+			})
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Transport() != ft {
+		t.Fatal("expected the bot to use the generated transport")
+	}
+
+	if err := b.RegisterCommand(fuzzy.NewCommand("where", "", func(ctx fuzzy.Context) {
+		vs, err := ctx.Bot().GetVoiceState(ctx.MessageEvent().GuildID, ctx.MessageEvent().Author.ID)
+		if err != nil {
+			_, _ = ctx.SendMessage(err.Error())
+			return
+		}
+		_, _ = ctx.SendMessage(vs.ChannelID)
+	})); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		user  string
+		reply string
+	}{
+		{"4000", "3001"},
+		{"4001", fuzzy.ErrUnknownVoiceState.Error()},
+	} {
+		ft.sent = nil
+		b.Inject(&discordgo.MessageCreate{Message: &discordgo.Message{
+			ID:        "6000",
+			GuildID:   "2000",
+			ChannelID: "3000",
+			Content:   "!where",
+			Author:    &discordgo.User{ID: tt.user},
+		}})
+		if len(ft.sent) != 1 || ft.sent[0].Content != tt.reply {
+			t.Fatalf("expected the reply %q to be sent through the transport got %v", tt.reply, ft.sent)
+		}
+	}
+}
@@ -4,15 +4,16 @@ import (
 	"io"
 	"sync"
 	"sync/atomic"
+	"time"
 
-	"github.com/bwmarrin/discordgo"
 	"github.com/fvdveen/fuzzy/internal/queue"
 )
 
 // VoiceHandlerGenerator creates a voicehandler
-type VoiceHandlerGenerator func(sess *discordgo.Session, bot *Bot, gid, voiceChanID, textChanID string, vi VoiceItem) VoiceHandler
+type VoiceHandlerGenerator func(t Transport, bot *Bot, gid, voiceChanID, textChanID string, vi VoiceItem) VoiceHandler
 
 // VoiceHandler handles voice commands
+// the indexes of the queue start at 0 and do not include the item that is playing
 type VoiceHandler interface {
 	Play(VoiceItem)
 
@@ -22,6 +23,23 @@ type VoiceHandler interface {
 	Resume()
 	Loop()
 	Repeat()
+
+	// Queue returns the items that will be played after the current one
+	Queue() []VoiceItem
+	// NowPlaying returns the item that is playing or nil
+	NowPlaying() VoiceItem
+	// Position returns how much of the item that is playing has been sent
+	Position() time.Duration
+	// Remove removes the item at the index from the queue
+	Remove(i int) error
+	// Move moves the item at index a to index b
+	Move(a, b int) error
+	// Insert puts the item at the index in the queue
+	Insert(i int, vi VoiceItem) error
+	// PlayNext puts the item at the front of the queue
+	PlayNext(VoiceItem)
+	Clear()
+	Shuffle()
 }
 
 // VoiceItem is a item that should be played in the VoiceHandler
@@ -33,41 +51,50 @@ type VoiceItem interface {
 }
 
 type defaultVoiceHandler struct {
+	// frames is the amount of frames of the current item that have been sent
+	// it is first so it is aligned for atomic access
+	frames int64
+
 	bot                          *Bot
-	sess                         *discordgo.Session
+	transport                    Transport
 	gid, voiceChanID, textChanID string
 	log                          Logger
-	voiceConn                    *discordgo.VoiceConnection
+	voiceConn                    VoiceConnection
 	mu                           sync.Mutex
 
-	queue *queue.Queue
+	queue      *queue.Queue
+	nowPlaying VoiceItem
+	stopped    bool
 
 	loop, repeat, paused atomic.Value
 
-	skipChan   chan interface{}
-	stopChan   chan interface{}
-	pauseChan  chan interface{}
-	resumeChan chan interface{}
-	loopChan   chan interface{}
-	repeatChan chan interface{}
+	// commands are handled in the order they are given
+	commands chan voiceCommand
 }
 
+// voiceCommand is a command given to the default voice handler
+type voiceCommand int
+
+const (
+	voiceSkip voiceCommand = iota
+	voiceStop
+	voicePause
+	voiceResume
+	voiceLoop
+	voiceRepeat
+)
+
 // DefaultVoiceHandler creates the default voice handler
-func DefaultVoiceHandler(sess *discordgo.Session, bot *Bot, gid, voiceChanID, textChanID string, vi VoiceItem) VoiceHandler {
+func DefaultVoiceHandler(t Transport, bot *Bot, gid, voiceChanID, textChanID string, vi VoiceItem) VoiceHandler {
 	vh := &defaultVoiceHandler{
 		gid:         gid,
 		voiceChanID: voiceChanID,
 		textChanID:  textChanID,
 		bot:         bot,
-		sess:        sess,
+		transport:   t,
 		queue:       queue.New(),
 		log:         bot.Generator().Logger(bot.Config().LogLevel),
-		skipChan:    make(chan interface{}, 1),
-		stopChan:    make(chan interface{}, 1),
-		pauseChan:   make(chan interface{}, 1),
-		resumeChan:  make(chan interface{}, 1),
-		loopChan:    make(chan interface{}, 1),
-		repeatChan:  make(chan interface{}, 1),
+		commands:    make(chan voiceCommand, 8),
 	}
 	vh.queue.PushBack(vi)
 
@@ -84,37 +111,86 @@ func (vh *defaultVoiceHandler) Play(vi VoiceItem) {
 	vh.queue.PushBack(vi)
 }
 
+func (vh *defaultVoiceHandler) Queue() []VoiceItem {
+	var vis []VoiceItem
+	for _, i := range vh.queue.Copy() {
+		if vi, ok := i.(VoiceItem); ok {
+			vis = append(vis, vi)
+		}
+	}
+	return vis
+}
+
+func (vh *defaultVoiceHandler) NowPlaying() VoiceItem {
+	vh.mu.Lock()
+	defer vh.mu.Unlock()
+
+	return vh.nowPlaying
+}
+
+func (vh *defaultVoiceHandler) Position() time.Duration {
+	return time.Duration(atomic.LoadInt64(&vh.frames)) * FrameDuration
+}
+
+func (vh *defaultVoiceHandler) Remove(i int) error {
+	return queueError(vh.queue.Remove(i))
+}
+
+func (vh *defaultVoiceHandler) Move(a, b int) error {
+	return queueError(vh.queue.Reorder(a, b))
+}
+
+func (vh *defaultVoiceHandler) Insert(i int, vi VoiceItem) error {
+	return queueError(vh.queue.Insert(i, vi))
+}
+
+func (vh *defaultVoiceHandler) PlayNext(vi VoiceItem) {
+	vh.queue.PushFront(vi)
+}
+
+func (vh *defaultVoiceHandler) Clear() {
+	vh.queue.Clear()
+}
+
+func (vh *defaultVoiceHandler) Shuffle() {
+	vh.queue.Shuffle()
+}
+
+// queueError converts errors of the queue to errors of the package
+func queueError(err error) error {
+	if err == queue.ErrOutOfBounds {
+		return ErrQueueIndex
+	}
+	return err
+}
+
 func (vh *defaultVoiceHandler) Skip() {
-	vh.skipChan <- 0
+	vh.commands <- voiceSkip
 }
 
 func (vh *defaultVoiceHandler) Stop() {
-	vh.stopChan <- 0
+	vh.commands <- voiceStop
 }
 
 func (vh *defaultVoiceHandler) Pause() {
-	vh.pauseChan <- 0
+	vh.commands <- voicePause
 }
 
 func (vh *defaultVoiceHandler) Resume() {
-	vh.resumeChan <- 0
+	vh.commands <- voiceResume
 }
 
 func (vh *defaultVoiceHandler) Loop() {
-	vh.loopChan <- 0
+	vh.commands <- voiceLoop
 }
 
 func (vh *defaultVoiceHandler) Repeat() {
-	vh.repeatChan <- 0
+	vh.commands <- voiceRepeat
 }
 
 func (vh *defaultVoiceHandler) handle() {
-	if vh.queue == nil {
-		return
-	}
-
 	var err error
-	vh.voiceConn, err = vh.sess.ChannelVoiceJoin(vh.gid, vh.voiceChanID, false, true)
+	vh.voiceConn, err = vh.transport.ChannelVoiceJoin(vh.gid, vh.voiceChanID, false, true)
 	if err != nil {
 		vh.log.Errorf("Could not open voice connection: %v", err)
 		vh.bot.DeleteVoiceHandler(vh.gid)
@@ -128,7 +204,11 @@ func (vh *defaultVoiceHandler) handle() {
 	}
 
 	for {
-		if vh.queue == nil || vh.queue.Length() == 0 {
+		vh.mu.Lock()
+		vi, ok := vh.queue.PopFront().(VoiceItem)
+		if vh.stopped || !ok {
+			vh.nowPlaying = nil
+			vh.mu.Unlock()
 			if err := vh.voiceConn.Disconnect(); err != nil {
 				vh.log.Errorf("Could not send disconnect from voice channel: %v", err)
 			}
@@ -136,15 +216,16 @@ func (vh *defaultVoiceHandler) handle() {
 
 			return
 		}
-		vi, ok := vh.queue.PopFront().(VoiceItem)
-		if !ok {
-			vh.log.Errorf("Could not convert voice item of type: %T to VoiceItem", vi)
-			continue
-		}
+		vh.nowPlaying = vi
+		atomic.StoreInt64(&vh.frames, 0)
+		vh.mu.Unlock()
 
-		if err := vh.playItem(vi); err != nil {
+		vh.bot.Events().Publish(&VoiceTrackStarted{GuildID: vh.gid, Item: vi})
+		err := vh.playItem(vi)
+		if err != nil {
 			vh.log.Errorf("Could not play voice item: %v", err)
 		}
+		vh.bot.Events().Publish(&VoiceTrackEnded{GuildID: vh.gid, Item: vi, Err: err})
 
 		if vh.repeat.Load().(bool) {
 			vi.ResetPlayback()
@@ -164,53 +245,71 @@ func (vh *defaultVoiceHandler) playItem(vi VoiceItem) error {
 		} else if err != nil {
 			return err
 		}
-		for vh.paused.Load().(bool) {
-			select {
-			case <-vh.pauseChan:
-				vh.paused.Store(true)
-			case <-vh.resumeChan:
-				vh.paused.Store(false)
-			case <-vh.loopChan:
-				vh.loop.Store(!vh.loop.Load().(bool))
-			case <-vh.repeatChan:
-				vh.repeat.Store(!vh.repeat.Load().(bool))
-			case <-vh.skipChan:
-				vh.paused.Store(false)
-				vi.ResetPlayback()
-				return nil
-			case <-vh.stopChan:
-				vh.mu.Lock()
-				vh.stop()
-				vh.mu.Unlock()
-				return nil
+		if !vh.sendFrame(vi, f) {
+			return nil
+		}
+	}
+}
+
+// sendFrame sends the frame when playback is not paused
+// pending commands are handled before the frame is sent so they apply right away
+// it returns false when the item was skipped or stopped
+func (vh *defaultVoiceHandler) sendFrame(vi VoiceItem, f []byte) bool {
+	for {
+		select {
+		case c := <-vh.commands:
+			if !vh.command(vi, c) {
+				return false
 			}
+			continue
+		default:
+		}
+
+		var send chan<- []byte
+		if !vh.paused.Load().(bool) {
+			send = vh.voiceConn.OpusSend()
 		}
 		select {
-		case <-vh.resumeChan:
-			vh.paused.Store(false)
-		case <-vh.pauseChan:
-			vh.paused.Store(true)
-		case <-vh.loopChan:
-			vh.loop.Store(!vh.loop.Load().(bool))
-		case <-vh.repeatChan:
-			vh.repeat.Store(!vh.repeat.Load().(bool))
-		case <-vh.skipChan:
-			vh.paused.Store(false)
-			vi.ResetPlayback()
-			return nil
-		case <-vh.stopChan:
-			vh.mu.Lock()
-			vh.stop()
-			vh.mu.Unlock()
-			return nil
-		case vh.voiceConn.OpusSend <- f:
+		case c := <-vh.commands:
+			if !vh.command(vi, c) {
+				return false
+			}
+		case send <- f:
+			atomic.AddInt64(&vh.frames, 1)
+			return true
 		}
 	}
 }
 
+// command handles a command sent to the handler
+// it returns false when the item was skipped or stopped
+func (vh *defaultVoiceHandler) command(vi VoiceItem, c voiceCommand) bool {
+	switch c {
+	case voicePause:
+		vh.paused.Store(true)
+	case voiceResume:
+		vh.paused.Store(false)
+	case voiceLoop:
+		vh.loop.Store(!vh.loop.Load().(bool))
+	case voiceRepeat:
+		vh.repeat.Store(!vh.repeat.Load().(bool))
+	case voiceSkip:
+		vh.paused.Store(false)
+		vi.ResetPlayback()
+		return false
+	case voiceStop:
+		vh.mu.Lock()
+		vh.stop()
+		vh.mu.Unlock()
+		return false
+	}
+	return true
+}
+
 func (vh *defaultVoiceHandler) stop() {
 	vh.paused.Store(false)
 	vh.loop.Store(false)
 	vh.repeat.Store(false)
-	vh.queue = nil
+	vh.queue.Clear()
+	vh.stopped = true
 }